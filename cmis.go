@@ -0,0 +1,144 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// CMIS lower page offsets
+const (
+	CMIS_ID_OFFSET             = 0x00
+	CMIS_REV_COMPLIANCE_OFFSET = 0x01
+	CMIS_MODULE_STATE_OFFSET   = 0x03
+	CMIS_CURR_TEMP_OFFSET      = 0x0e
+	CMIS_CURR_VCC_OFFSET       = 0x10
+	CMIS_MEDIA_TYPE_OFFSET     = 0x55
+	CMIS_APP_DESCS_OFFSET      = 0x56
+
+	CMIS_PAGE_LEN     = 0x80
+	CMIS_APP_DESC_LEN = 4
+	CMIS_APP_DESCS    = 8
+)
+
+// Module states of the bits 3-1 of byte 3
+const (
+	CMIS_MODULE_STATE_LOW_PWR = 0x01
+	CMIS_MODULE_STATE_PWR_UP  = 0x02
+	CMIS_MODULE_STATE_READY   = 0x03
+	CMIS_MODULE_STATE_PWR_DN  = 0x04
+	CMIS_MODULE_STATE_FAULT   = 0x05
+)
+
+var cmisModuleStateNames = map[uint8]string{
+	CMIS_MODULE_STATE_LOW_PWR: "ModuleLowPwr",
+	CMIS_MODULE_STATE_PWR_UP:  "ModulePwrUp",
+	CMIS_MODULE_STATE_READY:   "ModuleReady",
+	CMIS_MODULE_STATE_PWR_DN:  "ModulePwrDn",
+	CMIS_MODULE_STATE_FAULT:   "ModuleFault",
+}
+
+// Media types of byte 85
+const (
+	CMIS_MT_UNDEFINED      = 0x00
+	CMIS_MT_MMF            = 0x01
+	CMIS_MT_SMF            = 0x02
+	CMIS_MT_PASSIVE_COPPER = 0x03
+	CMIS_MT_ACTIVE_CABLES  = 0x04
+	CMIS_MT_BASE_T         = 0x05
+)
+
+var cmisMediaTypeNames = map[uint8]string{
+	CMIS_MT_UNDEFINED:      "Undefined",
+	CMIS_MT_MMF:            "Optical Interfaces: MMF",
+	CMIS_MT_SMF:            "Optical Interfaces: SMF",
+	CMIS_MT_PASSIVE_COPPER: "Passive Copper Cables",
+	CMIS_MT_ACTIVE_CABLES:  "Active Cables",
+	CMIS_MT_BASE_T:         "BASE-T",
+}
+
+// CMISApplication is an application advertised by a CMIS module, the
+// interface identifiers are defined by SFF-8024.
+type CMISApplication struct {
+	HostInterfaceID    uint8 `json:"host_interface_id"`
+	MediaInterfaceID   uint8 `json:"media_interface_id"`
+	HostLaneCount      uint8 `json:"host_lane_count"`
+	MediaLaneCount     uint8 `json:"media_lane_count"`
+	HostLaneAssignment uint8 `json:"host_lane_assignment"` // bitmask of the allowed first host lanes
+}
+
+// CMISModule contains the identification and the module level monitors of
+// a CMIS module like QSFP-DD or OSFP.
+type CMISModule struct {
+	Identifier    string            `json:"identifier"`
+	RevCompliance string            `json:"rev_compliance,omitempty"`
+	ModuleState   string            `json:"module_state,omitempty"`
+	MediaType     string            `json:"media_type,omitempty"`
+	Applications  []CMISApplication `json:"applications,omitempty"`
+	Temperature   float64           `json:"temperature"` // module temperature in degrees Celsius
+	Voltage       float64           `json:"voltage"`     // supply voltage in volts
+}
+
+func cmisShowApplications(id []byte) []CMISApplication {
+	var apps []CMISApplication
+
+	for i := 0; i < CMIS_APP_DESCS; i++ {
+		desc := id[CMIS_APP_DESCS_OFFSET+i*CMIS_APP_DESC_LEN:]
+
+		// an unused host interface identifier ends the list
+		if desc[0] == 0xff {
+			break
+		}
+
+		apps = append(apps, CMISApplication{
+			HostInterfaceID:    desc[0],
+			MediaInterfaceID:   desc[1],
+			HostLaneCount:      desc[2] >> 4,
+			MediaLaneCount:     desc[2] & 0x0f,
+			HostLaneAssignment: desc[3],
+		})
+	}
+
+	return apps
+}
+
+// ParseCMIS decodes the given CMIS module EEPROM lower page.
+func ParseCMIS(id []byte) (*CMISModule, error) {
+	if len(id) < CMIS_PAGE_LEN {
+		return nil, fmt.Errorf("CMIS EEPROM too short: %d bytes", len(id))
+	}
+
+	rev := id[CMIS_REV_COMPLIANCE_OFFSET]
+	state := (id[CMIS_MODULE_STATE_OFFSET] >> 1) & 0x07
+
+	// values are in units of 1/256 degree and 100 uV
+	return &CMISModule{
+		Identifier:    sff8024ShowIdentifier(id[CMIS_ID_OFFSET]),
+		RevCompliance: fmt.Sprintf("%d.%d", rev>>4, rev&0x0f),
+		ModuleState:   sff8024ShowValue(state, cmisModuleStateNames),
+		MediaType:     sff8024ShowValue(id[CMIS_MEDIA_TYPE_OFFSET], cmisMediaTypeNames),
+		Applications:  cmisShowApplications(id),
+		Temperature:   float64(int16(binary.BigEndian.Uint16(id[CMIS_CURR_TEMP_OFFSET:]))) / 256,
+		Voltage:       float64(binary.BigEndian.Uint16(id[CMIS_CURR_VCC_OFFSET:])) * 100e-6,
+	}, nil
+}
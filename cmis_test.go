@@ -0,0 +1,69 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func TestParseCMIS(t *testing.T) {
+	id := make([]byte, 2*CMIS_PAGE_LEN)
+	id[CMIS_ID_OFFSET] = SFF8024_ID_QSFP_DD
+	id[CMIS_REV_COMPLIANCE_OFFSET] = 0x50
+	id[CMIS_MODULE_STATE_OFFSET] = CMIS_MODULE_STATE_READY << 1
+	id[CMIS_MEDIA_TYPE_OFFSET] = CMIS_MT_SMF
+	// 400GAUI-8 to 400GBASE-DR4 and 100GAUI-2 to 100G-DR
+	copy(id[CMIS_APP_DESCS_OFFSET:], []byte{0x11, 0x1c, 0x84, 0x01, 0x0d, 0x14, 0x21, 0x55, 0xff})
+	// -1.5 C, 3.3 V
+	binary.BigEndian.PutUint16(id[CMIS_CURR_TEMP_OFFSET:], 0xfe80)
+	binary.BigEndian.PutUint16(id[CMIS_CURR_VCC_OFFSET:], 33000)
+
+	cmis, err := ParseCMIS(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !floatEquals(cmis.Temperature, -1.5) || !floatEquals(cmis.Voltage, 3.3) {
+		t.Errorf("unexpected temperature or voltage: %+v", cmis)
+	}
+	cmis.Temperature, cmis.Voltage = 0, 0
+
+	expected := &CMISModule{
+		Identifier:    "0x18 (QSFP-DD Double Density 8X Pluggable Transceiver)",
+		RevCompliance: "5.0",
+		ModuleState:   "0x03 (ModuleReady)",
+		MediaType:     "0x02 (Optical Interfaces: SMF)",
+		Applications: []CMISApplication{
+			{HostInterfaceID: 0x11, MediaInterfaceID: 0x1c, HostLaneCount: 8, MediaLaneCount: 4, HostLaneAssignment: 0x01},
+			{HostInterfaceID: 0x0d, MediaInterfaceID: 0x14, HostLaneCount: 2, MediaLaneCount: 1, HostLaneAssignment: 0x55},
+		},
+	}
+	if !reflect.DeepEqual(cmis, expected) {
+		t.Errorf("expected %+v, got %+v", expected, cmis)
+	}
+
+	if _, err := ParseCMIS(make([]byte, 64)); err == nil {
+		t.Error("expected an error for a short EEPROM")
+	}
+}
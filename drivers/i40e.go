@@ -0,0 +1,89 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+// Package drivers provides driver specific interpretation of the stats and
+// private flags exposed through the ethtool package.
+package drivers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/safchain/ethtool"
+)
+
+// Private flags of the Intel i40e/ice drivers
+const (
+	PRIV_FLAG_VF_VLAN_PRUNE      = "vf-vlan-pruning"
+	PRIV_FLAG_LINK_DOWN_ON_CLOSE = "link-down-on-close"
+	PRIV_FLAG_LEGACY_RX          = "legacy-rx"
+	PRIV_FLAG_DISABLE_FW_LLDP    = "disable-fw-lldp"
+	PRIV_FLAG_VEB_STATS          = "veb-stats"
+)
+
+// prefixes of the i40e/ice stats not related to the VSI of the interface
+var i40eNonVSIStatsPrefixes = []string{"port.", "veb."}
+
+// I40EStats returns the VSI statistics of an interface handled by the Intel
+// i40e/ice drivers, along with its private flags reported as
+// "priv_flag.<name>" with a value of 0 or 1.
+func I40EStats(e *ethtool.Ethtool, intf string) (map[string]uint64, error) {
+	driver, err := e.DriverName(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	if driver != "i40e" && driver != "ice" {
+		return nil, fmt.Errorf("unsupported driver %q, expected i40e or ice", driver)
+	}
+
+	stats, err := e.Stats(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := e.PrivFlags(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]uint64, len(stats)+len(flags))
+
+STATS:
+	for name, value := range stats {
+		for _, prefix := range i40eNonVSIStatsPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				continue STATS
+			}
+		}
+		result[name] = value
+	}
+
+	for name, active := range flags {
+		var value uint64
+		if active {
+			value = 1
+		}
+		result["priv_flag."+name] = value
+	}
+
+	return result, nil
+}
@@ -27,9 +27,17 @@ package ethtool
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 	"unsafe"
 
 	"golang.org/x/sys/unix"
@@ -47,10 +55,21 @@ const (
 
 // ethtool stats related constants.
 const (
-	ETH_GSTRING_LEN   = 32
-	ETH_SS_STATS      = 1
-	ETH_SS_PRIV_FLAGS = 2
-	ETH_SS_FEATURES   = 4
+	ETH_GSTRING_LEN       = 32
+	ETH_SS_TEST           = 0
+	ETH_SS_STATS          = 1
+	ETH_SS_PRIV_FLAGS     = 2
+	ETH_SS_FEATURES       = 4
+	ETH_SS_RSS_HASH_FUNCS = 5
+	ETH_SS_TUNABLES       = 6
+	ETH_SS_PHY_STATS      = 7
+
+	// standard stats string sets, only available through netlink
+	ETH_SS_STATS_STD      = 16
+	ETH_SS_STATS_ETH_PHY  = 17
+	ETH_SS_STATS_ETH_MAC  = 18
+	ETH_SS_STATS_ETH_CTRL = 19
+	ETH_SS_STATS_RMON     = 20
 
 	// CMD supported
 	ETHTOOL_GSET     = 0x00000001 /* Get settings. */
@@ -58,25 +77,41 @@ const (
 	ETHTOOL_GWOL     = 0x00000005 /* Get wake-on-lan options. */
 	ETHTOOL_SWOL     = 0x00000006 /* Set wake-on-lan options. */
 	ETHTOOL_GDRVINFO = 0x00000003 /* Get driver info. */
+	ETHTOOL_GREGS    = 0x00000004 /* Get NIC registers. */
 	ETHTOOL_GMSGLVL  = 0x00000007 /* Get driver message level */
 	ETHTOOL_SMSGLVL  = 0x00000008 /* Set driver msg level. */
+	ETHTOOL_NWAY_RST = 0x00000009 /* Restart autonegotiation. */
 
 	// Get link status for host, i.e. whether the interface *and* the
 	// physical port (if there is one) are up (ethtool_value).
 	ETHTOOL_GLINK         = 0x0000000a
+	ETHTOOL_GEEPROM       = 0x0000000b /* Get EEPROM data */
+	ETHTOOL_SEEPROM       = 0x0000000c /* Set EEPROM data. */
 	ETHTOOL_GCOALESCE     = 0x0000000e /* Get coalesce config */
 	ETHTOOL_SCOALESCE     = 0x0000000f /* Set coalesce config */
 	ETHTOOL_GRINGPARAM    = 0x00000010 /* Get ring parameters */
 	ETHTOOL_SRINGPARAM    = 0x00000011 /* Set ring parameters. */
 	ETHTOOL_GPAUSEPARAM   = 0x00000012 /* Get pause parameters */
 	ETHTOOL_SPAUSEPARAM   = 0x00000013 /* Set pause parameters. */
+	ETHTOOL_TEST          = 0x0000001a /* execute NIC self-test. */
 	ETHTOOL_GSTRINGS      = 0x0000001b /* Get specified string set */
+	ETHTOOL_PHYS_ID       = 0x0000001c /* identify the NIC */
 	ETHTOOL_GSTATS        = 0x0000001d /* Get NIC-specific statistics */
 	ETHTOOL_GPERMADDR     = 0x00000020 /* Get permanent hardware address */
 	ETHTOOL_GFLAGS        = 0x00000025 /* Get flags bitmap(ethtool_value) */
 	ETHTOOL_GPFLAGS       = 0x00000027 /* Get driver-private flags bitmap */
 	ETHTOOL_SPFLAGS       = 0x00000028 /* Set driver-private flags bitmap */
 	ETHTOOL_GSSET_INFO    = 0x00000037 /* Get string set info */
+	ETHTOOL_GRXFH         = 0x00000029 /* Get RX flow hash configuration */
+	ETHTOOL_GRXRINGS      = 0x0000002d /* Get RX rings available for LB */
+	ETHTOOL_GRXCLSRLCNT   = 0x0000002e /* Get RX class rule count */
+	ETHTOOL_GRXCLSRULE    = 0x0000002f /* Get RX classification rule */
+	ETHTOOL_GRXCLSRLALL   = 0x00000030 /* Get all RX classification rule */
+	ETHTOOL_SRXCLSRLDEL   = 0x00000031 /* Delete RX classification rule */
+	ETHTOOL_SRXCLSRLINS   = 0x00000032 /* Insert RX classification rule */
+	ETHTOOL_RESET         = 0x00000034 /* Reset hardware */
+	ETHTOOL_GRXFHINDIR    = 0x00000038 /* Get RX flow hash indir'n table */
+	ETHTOOL_SRXFHINDIR    = 0x00000039 /* Set RX flow hash indir'n table */
 	ETHTOOL_GFEATURES     = 0x0000003a /* Get device offload settings */
 	ETHTOOL_SFEATURES     = 0x0000003b /* Change device offload settings */
 	ETHTOOL_GCHANNELS     = 0x0000003c /* Get no of channels */
@@ -84,6 +119,234 @@ const (
 	ETHTOOL_GET_TS_INFO   = 0x00000041 /* Get time stamping and PHC info */
 	ETHTOOL_GMODULEINFO   = 0x00000042 /* Get plug-in module information */
 	ETHTOOL_GMODULEEEPROM = 0x00000043 /* Get plug-in module eeprom */
+	ETHTOOL_GEEE          = 0x00000044 /* Get EEE settings */
+	ETHTOOL_SEEE          = 0x00000045 /* Set EEE settings */
+	ETHTOOL_GRSSH         = 0x00000046 /* Get RX flow hash configuration */
+	ETHTOOL_SRSSH         = 0x00000047 /* Set RX flow hash configuration */
+	ETHTOOL_GPHYSTATS     = 0x0000004a /* get PHY-specific statistics */
+	ETHTOOL_GLINKSETTINGS = 0x0000004c /* Get ethtool_link_settings */
+	ETHTOOL_GTUNABLE      = 0x00000048 /* Get tunable configuration */
+	ETHTOOL_STUNABLE      = 0x00000049 /* Set tunable configuration */
+	ETHTOOL_PHY_GTUNABLE  = 0x0000004e /* Get PHY tunable configuration */
+	ETHTOOL_PHY_STUNABLE  = 0x0000004f /* Set PHY tunable configuration */
+	ETHTOOL_GFECPARAM     = 0x00000050 /* Get FEC settings */
+	ETHTOOL_SFECPARAM     = 0x00000051 /* Set FEC settings */
+)
+
+// names of the commands, used to report failing operations
+var ethtoolCmdNames = map[uint32]string{
+	ETHTOOL_GSET:          "ETHTOOL_GSET",
+	ETHTOOL_SSET:          "ETHTOOL_SSET",
+	ETHTOOL_GWOL:          "ETHTOOL_GWOL",
+	ETHTOOL_SWOL:          "ETHTOOL_SWOL",
+	ETHTOOL_GDRVINFO:      "ETHTOOL_GDRVINFO",
+	ETHTOOL_GREGS:         "ETHTOOL_GREGS",
+	ETHTOOL_GMSGLVL:       "ETHTOOL_GMSGLVL",
+	ETHTOOL_SMSGLVL:       "ETHTOOL_SMSGLVL",
+	ETHTOOL_NWAY_RST:      "ETHTOOL_NWAY_RST",
+	ETHTOOL_GLINK:         "ETHTOOL_GLINK",
+	ETHTOOL_GEEPROM:       "ETHTOOL_GEEPROM",
+	ETHTOOL_SEEPROM:       "ETHTOOL_SEEPROM",
+	ETHTOOL_GCOALESCE:     "ETHTOOL_GCOALESCE",
+	ETHTOOL_SCOALESCE:     "ETHTOOL_SCOALESCE",
+	ETHTOOL_GRINGPARAM:    "ETHTOOL_GRINGPARAM",
+	ETHTOOL_SRINGPARAM:    "ETHTOOL_SRINGPARAM",
+	ETHTOOL_GPAUSEPARAM:   "ETHTOOL_GPAUSEPARAM",
+	ETHTOOL_SPAUSEPARAM:   "ETHTOOL_SPAUSEPARAM",
+	ETHTOOL_TEST:          "ETHTOOL_TEST",
+	ETHTOOL_GSTRINGS:      "ETHTOOL_GSTRINGS",
+	ETHTOOL_PHYS_ID:       "ETHTOOL_PHYS_ID",
+	ETHTOOL_GSTATS:        "ETHTOOL_GSTATS",
+	ETHTOOL_GPERMADDR:     "ETHTOOL_GPERMADDR",
+	ETHTOOL_GFLAGS:        "ETHTOOL_GFLAGS",
+	ETHTOOL_GPFLAGS:       "ETHTOOL_GPFLAGS",
+	ETHTOOL_SPFLAGS:       "ETHTOOL_SPFLAGS",
+	ETHTOOL_GSSET_INFO:    "ETHTOOL_GSSET_INFO",
+	ETHTOOL_GRXFH:         "ETHTOOL_GRXFH",
+	ETHTOOL_GRXRINGS:      "ETHTOOL_GRXRINGS",
+	ETHTOOL_GRXCLSRLCNT:   "ETHTOOL_GRXCLSRLCNT",
+	ETHTOOL_GRXCLSRULE:    "ETHTOOL_GRXCLSRULE",
+	ETHTOOL_GRXCLSRLALL:   "ETHTOOL_GRXCLSRLALL",
+	ETHTOOL_SRXCLSRLDEL:   "ETHTOOL_SRXCLSRLDEL",
+	ETHTOOL_SRXCLSRLINS:   "ETHTOOL_SRXCLSRLINS",
+	ETHTOOL_RESET:         "ETHTOOL_RESET",
+	ETHTOOL_GRXFHINDIR:    "ETHTOOL_GRXFHINDIR",
+	ETHTOOL_SRXFHINDIR:    "ETHTOOL_SRXFHINDIR",
+	ETHTOOL_GFEATURES:     "ETHTOOL_GFEATURES",
+	ETHTOOL_SFEATURES:     "ETHTOOL_SFEATURES",
+	ETHTOOL_GCHANNELS:     "ETHTOOL_GCHANNELS",
+	ETHTOOL_SCHANNELS:     "ETHTOOL_SCHANNELS",
+	ETHTOOL_GET_TS_INFO:   "ETHTOOL_GET_TS_INFO",
+	ETHTOOL_GMODULEINFO:   "ETHTOOL_GMODULEINFO",
+	ETHTOOL_GMODULEEEPROM: "ETHTOOL_GMODULEEEPROM",
+	ETHTOOL_GEEE:          "ETHTOOL_GEEE",
+	ETHTOOL_SEEE:          "ETHTOOL_SEEE",
+	ETHTOOL_GRSSH:         "ETHTOOL_GRSSH",
+	ETHTOOL_SRSSH:         "ETHTOOL_SRSSH",
+	ETHTOOL_GPHYSTATS:     "ETHTOOL_GPHYSTATS",
+	ETHTOOL_GLINKSETTINGS: "ETHTOOL_GLINKSETTINGS",
+	ETHTOOL_GTUNABLE:      "ETHTOOL_GTUNABLE",
+	ETHTOOL_STUNABLE:      "ETHTOOL_STUNABLE",
+	ETHTOOL_PHY_GTUNABLE:  "ETHTOOL_PHY_GTUNABLE",
+	ETHTOOL_PHY_STUNABLE:  "ETHTOOL_PHY_STUNABLE",
+	ETHTOOL_GFECPARAM:     "ETHTOOL_GFECPARAM",
+	ETHTOOL_SFECPARAM:     "ETHTOOL_SFECPARAM",
+}
+
+func ethtoolCmdName(cmd uint32) string {
+	if name, ok := ethtoolCmdNames[cmd]; ok {
+		return name
+	}
+	return fmt.Sprintf("command %#x", cmd)
+}
+
+// Duplex is a duplex mode.
+type Duplex uint8
+
+// Duplex modes
+const (
+	DUPLEX_HALF    Duplex = 0x00
+	DUPLEX_FULL    Duplex = 0x01
+	DUPLEX_UNKNOWN Duplex = 0xff
+)
+
+var duplexNames = map[Duplex]string{
+	DUPLEX_HALF: "half",
+	DUPLEX_FULL: "full",
+}
+
+// DuplexName returns the name of the given duplex mode, "half", "full" or
+// "unknown".
+func DuplexName(v Duplex) string {
+	if name, ok := duplexNames[v]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// String returns the name of the duplex mode, see DuplexName.
+func (v Duplex) String() string {
+	return DuplexName(v)
+}
+
+// DuplexFromString returns the duplex mode of the given name, the reverse
+// of DuplexName. The name is case insensitive.
+func DuplexFromString(s string) (Duplex, error) {
+	for duplex, name := range duplexNames {
+		if strings.EqualFold(s, name) {
+			return duplex, nil
+		}
+	}
+	return DUPLEX_UNKNOWN, fmt.Errorf("unknown duplex mode %q", s)
+}
+
+// Port is a port type.
+type Port uint8
+
+// Port types
+const (
+	PORT_TP    Port = 0x00
+	PORT_AUI   Port = 0x01
+	PORT_BNC   Port = 0x02
+	PORT_MII   Port = 0x03
+	PORT_FIBRE Port = 0x04
+	PORT_DA    Port = 0x05
+	PORT_NONE  Port = 0xef
+	PORT_OTHER Port = 0xff
+)
+
+// port names as used by the ethtool command line
+var portNames = map[Port]string{
+	PORT_TP:    "tp",
+	PORT_AUI:   "aui",
+	PORT_BNC:   "bnc",
+	PORT_MII:   "mii",
+	PORT_FIBRE: "fibre",
+	PORT_DA:    "da",
+	PORT_NONE:  "none",
+	PORT_OTHER: "other",
+}
+
+// PortName returns the name of the given port type, as used by the ethtool
+// command line, e.g. "tp" or "fibre".
+func PortName(v Port) string {
+	if name, ok := portNames[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", v)
+}
+
+// String returns the name of the port type, see PortName.
+func (v Port) String() string {
+	return PortName(v)
+}
+
+// PortFromString returns the port type of the given name, the reverse of
+// PortName. The name is case insensitive.
+func PortFromString(s string) (Port, error) {
+	for port, name := range portNames {
+		if strings.EqualFold(s, name) {
+			return port, nil
+		}
+	}
+	return PORT_OTHER, fmt.Errorf("unknown port type %q", s)
+}
+
+// Auto-negotiation modes
+const (
+	AUTONEG_DISABLE = 0x00
+	AUTONEG_ENABLE  = 0x01
+)
+
+// Link speeds in Mbps, see uapi/linux/ethtool.h
+const (
+	SPEED_10      uint32 = 10
+	SPEED_100     uint32 = 100
+	SPEED_1000    uint32 = 1000
+	SPEED_2500    uint32 = 2500
+	SPEED_5000    uint32 = 5000
+	SPEED_10000   uint32 = 10000
+	SPEED_14000   uint32 = 14000
+	SPEED_20000   uint32 = 20000
+	SPEED_25000   uint32 = 25000
+	SPEED_40000   uint32 = 40000
+	SPEED_50000   uint32 = 50000
+	SPEED_56000   uint32 = 56000
+	SPEED_100000  uint32 = 100000
+	SPEED_200000  uint32 = 200000
+	SPEED_400000  uint32 = 400000
+	SPEED_800000  uint32 = 800000
+	SPEED_UNKNOWN uint32 = 0xffffffff
+)
+
+// Device flags returned by ETHTOOL_GFLAGS
+const (
+	ETH_FLAG_TXVLAN = 1 << 7  /* TX VLAN offload enabled */
+	ETH_FLAG_RXVLAN = 1 << 8  /* RX VLAN offload enabled */
+	ETH_FLAG_LRO    = 1 << 15 /* LRO is enabled */
+	ETH_FLAG_NTUPLE = 1 << 27 /* N-tuple filters enabled */
+	ETH_FLAG_RXHASH = 1 << 28 /* RX hashing enabled */
+)
+
+// Reset flags, see ethtool_reset_flags in uapi/linux/ethtool.h. The
+// ETH_RESET_* values reset components dedicated to the interface, shift them
+// by ETH_RESET_SHARED_SHIFT to also reset components shared with other
+// interfaces.
+const (
+	ETH_RESET_MGMT    = 1 << 0 /* Management processor */
+	ETH_RESET_IRQ     = 1 << 1 /* Interrupt requester */
+	ETH_RESET_DMA     = 1 << 2 /* DMA engine */
+	ETH_RESET_FILTER  = 1 << 3 /* Filtering/flow direction */
+	ETH_RESET_OFFLOAD = 1 << 4 /* Protocol offload */
+	ETH_RESET_MAC     = 1 << 5 /* Media access controller */
+	ETH_RESET_PHY     = 1 << 6 /* Transceiver/PHY */
+	ETH_RESET_RAM     = 1 << 7 /* RAM shared between multiple components */
+	ETH_RESET_AP      = 1 << 8 /* Application processor */
+
+	ETH_RESET_DEDICATED = 0x0000ffff /* All components dedicated to this interface */
+	ETH_RESET_ALL       = 0xffffffff /* All components used by this interface, even if shared */
+
+	ETH_RESET_SHARED_SHIFT = 16
 )
 
 // MAX_GSTRINGS maximum number of stats entries that ethtool can
@@ -95,16 +358,77 @@ const (
 	PERMADDR_LEN       = 32
 )
 
+// Plug-in module types and EEPROM lengths, see uapi/linux/ethtool.h
+const (
+	ETH_MODULE_SFF_8079     = 0x1
+	ETH_MODULE_SFF_8079_LEN = 256
+	ETH_MODULE_SFF_8472     = 0x2
+	ETH_MODULE_SFF_8472_LEN = 512
+	ETH_MODULE_SFF_8636     = 0x3
+	ETH_MODULE_SFF_8636_LEN = 256
+	ETH_MODULE_SFF_8436     = 0x4
+	ETH_MODULE_SFF_8436_LEN = 256
+
+	ETH_MODULE_SFF_8636_MAX_LEN = 640
+	ETH_MODULE_SFF_8436_MAX_LEN = 640
+
+	// upper pages follow the lower page and the upper page 00h in the
+	// module EEPROM dump
+	ETH_MODULE_PAGE_LEN = 128
+)
+
 // ethtool sset_info related constants
 const (
 	MAX_SSET_INFO = 64
 )
 
+// nativeEndian is the byte order of the kernel structures
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
 type ifreq struct {
 	ifr_name [IFNAMSIZ]byte
 	ifr_data uintptr
 }
 
+type ifreqMTU struct {
+	ifr_name [IFNAMSIZ]byte
+	ifr_mtu  int32
+	pad      [20]byte
+}
+
+// MTU boundaries accepted by SetMTU
+const (
+	MIN_MTU = 68
+	MAX_MTU = 65536
+)
+
+// following structure comes from uapi/linux/ethtool.h, the register dump
+// follows it in memory
+type ethtoolRegs struct {
+	cmd     uint32
+	version uint32
+	len     uint32
+}
+
+// ethtool_gstrings and ethtool_stats headers, sized buffers follow them in
+// memory
+type ethtoolGStringsHdr struct {
+	cmd        uint32
+	string_set uint32
+	len        uint32
+}
+
+type ethtoolStatsHdr struct {
+	cmd     uint32
+	n_stats uint32
+}
+
 // following structures comes from uapi/linux/ethtool.h
 type ethtoolSsetInfo struct {
 	cmd       uint32
@@ -155,58 +479,122 @@ type ethtoolDrvInfo struct {
 // DrvInfo contains driver information
 // ethtool.h v3.5: struct ethtool_drvinfo
 type DrvInfo struct {
-	Cmd         uint32
-	Driver      string
-	Version     string
-	FwVersion   string
-	BusInfo     string
-	EromVersion string
-	Reserved2   string
-	NPrivFlags  uint32
-	NStats      uint32
-	TestInfoLen uint32
-	EedumpLen   uint32
-	RegdumpLen  uint32
+	Cmd         uint32 `json:"-"`
+	Driver      string `json:"driver"`
+	Version     string `json:"version,omitempty"`
+	FwVersion   string `json:"fw_version,omitempty"`
+	BusInfo     string `json:"bus_info,omitempty"`
+	EromVersion string `json:"erom_version,omitempty"`
+	Reserved2   string `json:"-"`
+	NPrivFlags  uint32 `json:"n_priv_flags"`
+	NStats      uint32 `json:"n_stats"`
+	TestInfoLen uint32 `json:"test_info_len"`
+	EedumpLen   uint32 `json:"eedump_len"`
+	RegdumpLen  uint32 `json:"regdump_len"`
+}
+
+// String returns the driver information the way `ethtool -i` does.
+func (d DrvInfo) String() string {
+	yesNo := func(v bool) string {
+		if v {
+			return "yes"
+		}
+		return "no"
+	}
+
+	return fmt.Sprintf("driver: %s\n"+
+		"version: %s\n"+
+		"firmware-version: %s\n"+
+		"expansion-rom-version: %s\n"+
+		"bus-info: %s\n"+
+		"supports-statistics: %s\n"+
+		"supports-test: %s\n"+
+		"supports-eeprom-access: %s\n"+
+		"supports-register-dump: %s\n"+
+		"supports-priv-flags: %s",
+		d.Driver, d.Version, d.FwVersion, d.EromVersion, d.BusInfo,
+		yesNo(d.NStats != 0), yesNo(d.TestInfoLen != 0), yesNo(d.EedumpLen != 0),
+		yesNo(d.RegdumpLen != 0), yesNo(d.NPrivFlags != 0))
 }
 
 // Channels contains the number of channels for a given interface.
 type Channels struct {
-	Cmd           uint32
-	MaxRx         uint32
-	MaxTx         uint32
-	MaxOther      uint32
-	MaxCombined   uint32
-	RxCount       uint32
-	TxCount       uint32
-	OtherCount    uint32
-	CombinedCount uint32
+	Cmd           uint32 `json:"-"`
+	MaxRx         uint32 `json:"max_rx"`
+	MaxTx         uint32 `json:"max_tx"`
+	MaxOther      uint32 `json:"max_other"`
+	MaxCombined   uint32 `json:"max_combined"`
+	RxCount       uint32 `json:"rx_count"`
+	TxCount       uint32 `json:"tx_count"`
+	OtherCount    uint32 `json:"other_count"`
+	CombinedCount uint32 `json:"combined_count"`
+}
+
+// String returns the channels the way `ethtool -l` does.
+func (c Channels) String() string {
+	count := func(v uint32) string {
+		if v == 0 {
+			return "n/a"
+		}
+		return fmt.Sprint(v)
+	}
+
+	return fmt.Sprintf("Pre-set maximums:\n"+
+		"RX:\t\t%s\nTX:\t\t%s\nOther:\t\t%s\nCombined:\t%s\n"+
+		"Current hardware settings:\n"+
+		"RX:\t\t%s\nTX:\t\t%s\nOther:\t\t%s\nCombined:\t%s",
+		count(c.MaxRx), count(c.MaxTx), count(c.MaxOther), count(c.MaxCombined),
+		count(c.RxCount), count(c.TxCount), count(c.OtherCount), count(c.CombinedCount))
 }
 
 // Coalesce is a coalesce config for an interface
 type Coalesce struct {
-	Cmd                      uint32
-	RxCoalesceUsecs          uint32
-	RxMaxCoalescedFrames     uint32
-	RxCoalesceUsecsIrq       uint32
-	RxMaxCoalescedFramesIrq  uint32
-	TxCoalesceUsecs          uint32
-	TxMaxCoalescedFrames     uint32
-	TxCoalesceUsecsIrq       uint32
-	TxMaxCoalescedFramesIrq  uint32
-	StatsBlockCoalesceUsecs  uint32
-	UseAdaptiveRxCoalesce    uint32
-	UseAdaptiveTxCoalesce    uint32
-	PktRateLow               uint32
-	RxCoalesceUsecsLow       uint32
-	RxMaxCoalescedFramesLow  uint32
-	TxCoalesceUsecsLow       uint32
-	TxMaxCoalescedFramesLow  uint32
-	PktRateHigh              uint32
-	RxCoalesceUsecsHigh      uint32
-	RxMaxCoalescedFramesHigh uint32
-	TxCoalesceUsecsHigh      uint32
-	TxMaxCoalescedFramesHigh uint32
-	RateSampleInterval       uint32
+	Cmd                      uint32 `json:"-"`
+	RxCoalesceUsecs          uint32 `json:"rx_coalesce_usecs"`
+	RxMaxCoalescedFrames     uint32 `json:"rx_max_coalesced_frames"`
+	RxCoalesceUsecsIrq       uint32 `json:"rx_coalesce_usecs_irq"`
+	RxMaxCoalescedFramesIrq  uint32 `json:"rx_max_coalesced_frames_irq"`
+	TxCoalesceUsecs          uint32 `json:"tx_coalesce_usecs"`
+	TxMaxCoalescedFrames     uint32 `json:"tx_max_coalesced_frames"`
+	TxCoalesceUsecsIrq       uint32 `json:"tx_coalesce_usecs_irq"`
+	TxMaxCoalescedFramesIrq  uint32 `json:"tx_max_coalesced_frames_irq"`
+	StatsBlockCoalesceUsecs  uint32 `json:"stats_block_coalesce_usecs"`
+	UseAdaptiveRxCoalesce    uint32 `json:"use_adaptive_rx_coalesce"`
+	UseAdaptiveTxCoalesce    uint32 `json:"use_adaptive_tx_coalesce"`
+	PktRateLow               uint32 `json:"pkt_rate_low"`
+	RxCoalesceUsecsLow       uint32 `json:"rx_coalesce_usecs_low"`
+	RxMaxCoalescedFramesLow  uint32 `json:"rx_max_coalesced_frames_low"`
+	TxCoalesceUsecsLow       uint32 `json:"tx_coalesce_usecs_low"`
+	TxMaxCoalescedFramesLow  uint32 `json:"tx_max_coalesced_frames_low"`
+	PktRateHigh              uint32 `json:"pkt_rate_high"`
+	RxCoalesceUsecsHigh      uint32 `json:"rx_coalesce_usecs_high"`
+	RxMaxCoalescedFramesHigh uint32 `json:"rx_max_coalesced_frames_high"`
+	TxCoalesceUsecsHigh      uint32 `json:"tx_coalesce_usecs_high"`
+	TxMaxCoalescedFramesHigh uint32 `json:"tx_max_coalesced_frames_high"`
+	RateSampleInterval       uint32 `json:"rate_sample_interval"`
+}
+
+// String returns the coalescing parameters the way `ethtool -c` does.
+func (c Coalesce) String() string {
+	onOff := func(v uint32) string {
+		if v != 0 {
+			return "on"
+		}
+		return "off"
+	}
+
+	return fmt.Sprintf("Adaptive RX: %s  TX: %s\n"+
+		"stats-block-usecs: %d\nsample-interval: %d\npkt-rate-low: %d\npkt-rate-high: %d\n\n"+
+		"rx-usecs: %d\nrx-frames: %d\nrx-usecs-irq: %d\nrx-frames-irq: %d\n\n"+
+		"tx-usecs: %d\ntx-frames: %d\ntx-usecs-irq: %d\ntx-frames-irq: %d\n\n"+
+		"rx-usecs-low: %d\nrx-frame-low: %d\ntx-usecs-low: %d\ntx-frame-low: %d\n\n"+
+		"rx-usecs-high: %d\nrx-frame-high: %d\ntx-usecs-high: %d\ntx-frame-high: %d",
+		onOff(c.UseAdaptiveRxCoalesce), onOff(c.UseAdaptiveTxCoalesce),
+		c.StatsBlockCoalesceUsecs, c.RateSampleInterval, c.PktRateLow, c.PktRateHigh,
+		c.RxCoalesceUsecs, c.RxMaxCoalescedFrames, c.RxCoalesceUsecsIrq, c.RxMaxCoalescedFramesIrq,
+		c.TxCoalesceUsecs, c.TxMaxCoalescedFrames, c.TxCoalesceUsecsIrq, c.TxMaxCoalescedFramesIrq,
+		c.RxCoalesceUsecsLow, c.RxMaxCoalescedFramesLow, c.TxCoalesceUsecsLow, c.TxMaxCoalescedFramesLow,
+		c.RxCoalesceUsecsHigh, c.RxMaxCoalescedFramesHigh, c.TxCoalesceUsecsHigh, c.TxMaxCoalescedFramesHigh)
 }
 
 // WoL options
@@ -218,8 +606,37 @@ const (
 	WAKE_ARP         = 1 << 4
 	WAKE_MAGIC       = 1 << 5
 	WAKE_MAGICSECURE = 1 << 6 // only meaningful if WAKE_MAGIC
+	WAKE_FILTER      = 1 << 7
+
+	SOPASS_MAX = 6
+)
+
+// WoL modes used by WOL
+const (
+	WOL_MODE_PHY         = WAKE_PHY
+	WOL_MODE_UCAST       = WAKE_UCAST
+	WOL_MODE_MCAST       = WAKE_MCAST
+	WOL_MODE_BCAST       = WAKE_BCAST
+	WOL_MODE_ARP         = WAKE_ARP
+	WOL_MODE_MAGIC       = WAKE_MAGIC
+	WOL_MODE_MAGICSECURE = WAKE_MAGICSECURE
+	WOL_MODE_FILTER      = WAKE_FILTER
 )
 
+var wolModeNames = []struct {
+	mode uint32
+	name string
+}{
+	{WOL_MODE_PHY, "phy"},
+	{WOL_MODE_UCAST, "ucast"},
+	{WOL_MODE_MCAST, "mcast"},
+	{WOL_MODE_BCAST, "bcast"},
+	{WOL_MODE_ARP, "arp"},
+	{WOL_MODE_MAGIC, "magic"},
+	{WOL_MODE_MAGICSECURE, "magicsecure"},
+	{WOL_MODE_FILTER, "filter"},
+}
+
 var WoLMap = map[uint32]string{
 	WAKE_PHY:         "p", // Wake on PHY activity
 	WAKE_UCAST:       "u", // Wake on unicast messages
@@ -234,9 +651,28 @@ var WoLMap = map[uint32]string{
 
 // WakeOnLan contains WoL config for an interface
 type WakeOnLan struct {
-	Cmd       uint32 // ETHTOOL_GWOL or ETHTOOL_SWOL
-	Supported uint32 // r/o bitmask of WAKE_* flags for supported WoL modes
-	Opts      uint32 // Bitmask of WAKE_* flags for enabled WoL modes
+	Cmd       uint32 `json:"-"`         // ETHTOOL_GWOL or ETHTOOL_SWOL
+	Supported uint32 `json:"supported"` // r/o bitmask of WAKE_* flags for supported WoL modes
+	Opts      uint32 `json:"opts"`      // Bitmask of WAKE_* flags for enabled WoL modes
+	sopass    [SOPASS_MAX]byte
+}
+
+// WOL contains the Wake-on-LAN config of an interface
+type WOL struct {
+	Supported uint32           `json:"supported"` // r/o bitmask of WOL_MODE_* flags for supported modes
+	Active    uint32           `json:"active"`    // bitmask of WOL_MODE_* flags for enabled modes
+	SoPass    [SOPASS_MAX]byte `json:"so_pass"`   // SecureOn password used by WOL_MODE_MAGICSECURE
+}
+
+// WOLModeNames returns the names of the WOL_MODE_* flags set in the given bitmask.
+func WOLModeNames(v uint32) []string {
+	var names []string
+	for _, mode := range wolModeNames {
+		if v&mode.mode != 0 {
+			names = append(names, mode.name)
+		}
+	}
+	return names
 }
 
 // Timestamping options
@@ -315,15 +751,30 @@ const (
 
 // TimestampingInformation contains PTP timetstapming information
 type TimestampingInformation struct {
-	Cmd            uint32
-	SoTimestamping uint32 /* SOF_TIMESTAMPING_* bitmask */
-	PhcIndex       int32
-	TxTypes        uint32 /* HWTSTAMP_TX_* */
+	Cmd            uint32 `json:"-"`
+	SoTimestamping uint32 `json:"so_timestamping"` /* SOF_TIMESTAMPING_* bitmask */
+	PhcIndex       int32  `json:"phc_index"`
+	TxTypes        uint32 `json:"tx_types"` /* HWTSTAMP_TX_* */
 	txReserved     [3]uint32
-	RxFilters      uint32 /* HWTSTAMP_FILTER_ */
+	RxFilters      uint32 `json:"rx_filters"` /* HWTSTAMP_FILTER_ */
 	rxReserved     [3]uint32
 }
 
+// TSInfo contains the timestamping capabilities of an interface.
+type TSInfo struct {
+	SoTimestamping uint32 `json:"so_timestamping"` // SOF_TIMESTAMPING_* bitmask
+	PhcIndex       int32  `json:"phc_index"`       // PTP hardware clock index, -1 if none
+	TxTypes        uint32 `json:"tx_types"`        // bitmask of the supported HWTSTAMP_TX_* modes
+	RxFilters      uint32 `json:"rx_filters"`      // bitmask of the supported HWTSTAMP_FILTER_* modes
+}
+
+// HasHardwareTimestamps returns whether hardware timestamps can be both
+// generated and reported for transmitted and received packets.
+func (t TSInfo) HasHardwareTimestamps() bool {
+	required := uint32(SOF_TIMESTAMPING_TX_HARDWARE | SOF_TIMESTAMPING_RX_HARDWARE | SOF_TIMESTAMPING_RAW_HARDWARE)
+	return t.SoTimestamping&required == required
+}
+
 type ethtoolGStrings struct {
 	cmd        uint32
 	string_set uint32
@@ -342,7 +793,6 @@ type ethtoolEeprom struct {
 	magic  uint32
 	offset uint32
 	len    uint32
-	data   [EEPROM_LEN]byte
 }
 
 type ethtoolModInfo struct {
@@ -352,6 +802,27 @@ type ethtoolModInfo struct {
 	reserved   [8]uint32
 }
 
+// ModInfo contains plug-in module information
+type ModInfo struct {
+	Type      uint32 `json:"type"` // ETH_MODULE_SFF_* standard of the module EEPROM
+	EEPROMLen uint32 `json:"eeprom_len"`
+}
+
+var modInfoTypeNames = map[uint32]string{
+	ETH_MODULE_SFF_8079: "SFF-8079",
+	ETH_MODULE_SFF_8472: "SFF-8472",
+	ETH_MODULE_SFF_8636: "SFF-8636",
+	ETH_MODULE_SFF_8436: "SFF-8436",
+}
+
+// TypeName returns the name of the standard of the module EEPROM.
+func (m ModInfo) TypeName() string {
+	if name, ok := modInfoTypeNames[m.Type]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (0x%x)", m.Type)
+}
+
 type ethtoolLink struct {
 	cmd  uint32
 	data uint32
@@ -365,28 +836,36 @@ type ethtoolPermAddr struct {
 
 // Ring is a ring config for an interface
 type Ring struct {
-	Cmd               uint32
-	RxMaxPending      uint32
-	RxMiniMaxPending  uint32
-	RxJumboMaxPending uint32
-	TxMaxPending      uint32
-	RxPending         uint32
-	RxMiniPending     uint32
-	RxJumboPending    uint32
-	TxPending         uint32
+	Cmd               uint32 `json:"-"`
+	RxMaxPending      uint32 `json:"rx_max_pending"`
+	RxMiniMaxPending  uint32 `json:"rx_mini_max_pending"`
+	RxJumboMaxPending uint32 `json:"rx_jumbo_max_pending"`
+	TxMaxPending      uint32 `json:"tx_max_pending"`
+	RxPending         uint32 `json:"rx_pending"`
+	RxMiniPending     uint32 `json:"rx_mini_pending"`
+	RxJumboPending    uint32 `json:"rx_jumbo_pending"`
+	TxPending         uint32 `json:"tx_pending"`
 }
 
 // Pause is a pause config for an interface
 type Pause struct {
-	Cmd     uint32
-	Autoneg uint32
-	RxPause uint32
-	TxPause uint32
+	Cmd     uint32 `json:"-"`
+	Autoneg uint32 `json:"autoneg"`
+	RxPause uint32 `json:"rx_pause"`
+	TxPause uint32 `json:"tx_pause"`
 }
 
 // Ethtool is a struct that contains the file descriptor for the ethtool
 type Ethtool struct {
-	fd int
+	fd     int
+	family int
+
+	retryAttempts int
+	retryDelay    time.Duration
+
+	ntupleCacheLock sync.Mutex
+	ntupleCache     map[ntupleCacheKey]ntupleCacheEntry
+	ntupleCacheGen  uint64
 }
 
 // Convert zero-terminated array of chars (string in C) to a Go string.
@@ -416,6 +895,36 @@ func (e *Ethtool) BusInfo(intf string) (string, error) {
 	return goString(info.bus_info[:]), nil
 }
 
+// ListInterfaces returns the names of the interfaces supporting ethtool
+// operations, the ones reporting driver information.
+func (e *Ethtool) ListInterfaces() ([]string, error) {
+	return e.listInterfaces(func(ethtoolDrvInfo) bool { return true })
+}
+
+// ListInterfacesByDriver returns the names of the interfaces handled by
+// the given driver.
+func (e *Ethtool) ListInterfacesByDriver(driver string) ([]string, error) {
+	return e.listInterfaces(func(info ethtoolDrvInfo) bool {
+		return goString(info.driver[:]) == driver
+	})
+}
+
+func (e *Ethtool) listInterfaces(filter func(ethtoolDrvInfo) bool) ([]string, error) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, intf := range intfs {
+		if info, err := e.getDriverInfo(intf.Name); err == nil && filter(info) {
+			names = append(names, intf.Name)
+		}
+	}
+
+	return names, nil
+}
+
 // ModuleEeprom returns Eeprom information of the given interface name.
 func (e *Ethtool) ModuleEeprom(intf string) ([]byte, error) {
 	eeprom, _, err := e.getModuleEeprom(intf)
@@ -423,7 +932,20 @@ func (e *Ethtool) ModuleEeprom(intf string) ([]byte, error) {
 		return nil, err
 	}
 
-	return eeprom.data[:eeprom.len], nil
+	return eeprom, nil
+}
+
+// ModuleInfo returns plug-in module information of the given interface name.
+func (e *Ethtool) ModuleInfo(intf string) (ModInfo, error) {
+	modInfo, err := e.getModuleInfo(intf)
+	if err != nil {
+		return ModInfo{}, err
+	}
+
+	return ModInfo{
+		Type:      modInfo.tpe,
+		EEPROMLen: modInfo.eeprom_len,
+	}, nil
 }
 
 // ModuleEeprom returns Eeprom information of the given interface name.
@@ -433,7 +955,124 @@ func (e *Ethtool) ModuleEepromHex(intf string) (string, error) {
 		return "", err
 	}
 
-	return hex.EncodeToString(eeprom.data[:eeprom.len]), nil
+	return hex.EncodeToString(eeprom), nil
+}
+
+// ModuleEepromBytes returns length bytes of the module EEPROM of the given
+// interface name, starting at the given offset of the EEPROM dump.
+func (e *Ethtool) ModuleEepromBytes(intf string, offset, length uint32) ([]byte, error) {
+	modInfo, err := e.getModuleInfo(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkEepromRange(offset, uint64(length), modInfo.eeprom_len); err != nil {
+		return nil, err
+	}
+
+	return e.readModuleEeprom(intf, offset, length)
+}
+
+// ModuleEepromPage returns length bytes of the given page of the module
+// EEPROM of the given interface name. The offset is the byte address as
+// defined by the module standards, below 128 for the lower page, which is
+// the same for every page, and from 128 to 255 for the upper page.
+func (e *Ethtool) ModuleEepromPage(intf string, page uint8, offset, length uint32) ([]byte, error) {
+	if offset+length > 2*ETH_MODULE_PAGE_LEN || offset+length < offset {
+		return nil, fmt.Errorf("invalid module EEPROM range: offset %d, length %d", offset, length)
+	}
+
+	if offset >= ETH_MODULE_PAGE_LEN {
+		offset += uint32(page) * ETH_MODULE_PAGE_LEN
+	} else if page != 0 && offset+length > ETH_MODULE_PAGE_LEN {
+		return nil, fmt.Errorf("module EEPROM range crosses the lower page: offset %d, length %d", offset, length)
+	}
+
+	return e.readModuleEeprom(intf, offset, length)
+}
+
+// GetEepromBytes returns length bytes, starting at the given offset, of the
+// EEPROM of the NIC of the given interface name, as opposed to the EEPROM of
+// its plug-in module, see ModuleEepromBytes.
+func (e *Ethtool) GetEepromBytes(intf string, offset, length uint32) ([]byte, error) {
+	drvinfo, err := e.getDriverInfo(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkEepromRange(offset, uint64(length), drvinfo.eedump_len); err != nil {
+		return nil, err
+	}
+
+	return e.readEeprom(intf, ETHTOOL_GEEPROM, offset, length)
+}
+
+// SetEepromBytes writes data at the given offset of the EEPROM of the NIC of
+// the given interface name. The magic value, specific to each driver, is
+// required by some of them to protect against accidental writes; it is
+// usually the one reported in the header of `ethtool -e`.
+func (e *Ethtool) SetEepromBytes(intf string, offset uint32, data []byte, magic uint32) error {
+	drvinfo, err := e.getDriverInfo(intf)
+	if err != nil {
+		return err
+	}
+
+	if err := checkEepromRange(offset, uint64(len(data)), drvinfo.eedump_len); err != nil {
+		return err
+	}
+
+	hdrLen := uint32(unsafe.Sizeof(ethtoolEeprom{}))
+	buf := make([]byte, hdrLen+uint32(len(data)))
+
+	eeprom := (*ethtoolEeprom)(unsafe.Pointer(&buf[0]))
+	eeprom.cmd = ETHTOOL_SEEPROM
+	eeprom.magic = magic
+	eeprom.offset = offset
+	eeprom.len = uint32(len(data))
+	copy(buf[hdrLen:], data)
+
+	return e.ioctl(intf, unsafe.Pointer(&buf[0]))
+}
+
+// GetRegDump returns the raw register dump of the given interface name.
+func (e *Ethtool) GetRegDump(intf string) ([]byte, error) {
+	drvinfo, err := e.getDriverInfo(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	if drvinfo.regdump_len == 0 {
+		return nil, mapErrno(unix.EOPNOTSUPP)
+	}
+
+	hdrLen := uint32(unsafe.Sizeof(ethtoolRegs{}))
+	buf := make([]byte, hdrLen+drvinfo.regdump_len)
+
+	regs := (*ethtoolRegs)(unsafe.Pointer(&buf[0]))
+	regs.cmd = ETHTOOL_GREGS
+	regs.len = drvinfo.regdump_len
+
+	if err := e.ioctl(intf, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, err
+	}
+
+	// the driver may dump less than announced
+	if regs.len > drvinfo.regdump_len {
+		return nil, fmt.Errorf("invalid register dump length %d, expected at most %d", regs.len, drvinfo.regdump_len)
+	}
+
+	return buf[hdrLen : hdrLen+regs.len], nil
+}
+
+// GetRegDumpHex returns the register dump of the given interface name,
+// hex encoded.
+func (e *Ethtool) GetRegDumpHex(intf string) (string, error) {
+	regs, err := e.GetRegDump(intf)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(regs), nil
 }
 
 // DriverInfo returns driver information of the given interface name.
@@ -491,13 +1130,114 @@ func (e *Ethtool) GetCoalesce(intf string) (Coalesce, error) {
 	return coalesce, nil
 }
 
-// SetCoalesce sets the coalesce config for the given interface name.
+// Validate checks the consistency of the adaptive coalescing thresholds.
+// A zero high threshold is considered as not set and is not checked.
+func (c Coalesce) Validate() error {
+	type threshold struct {
+		name      string
+		low, high uint32
+	}
+
+	var thresholds []threshold
+	if c.UseAdaptiveRxCoalesce != 0 || c.UseAdaptiveTxCoalesce != 0 {
+		thresholds = append(thresholds, threshold{"pkt-rate", c.PktRateLow, c.PktRateHigh})
+	}
+	if c.UseAdaptiveRxCoalesce != 0 {
+		thresholds = append(thresholds,
+			threshold{"rx-usecs", c.RxCoalesceUsecsLow, c.RxCoalesceUsecsHigh},
+			threshold{"rx-frames", c.RxMaxCoalescedFramesLow, c.RxMaxCoalescedFramesHigh},
+		)
+	}
+	if c.UseAdaptiveTxCoalesce != 0 {
+		thresholds = append(thresholds,
+			threshold{"tx-usecs", c.TxCoalesceUsecsLow, c.TxCoalesceUsecsHigh},
+			threshold{"tx-frames", c.TxMaxCoalescedFramesLow, c.TxMaxCoalescedFramesHigh},
+		)
+	}
+
+	for _, t := range thresholds {
+		if t.high != 0 && t.low > t.high {
+			return fmt.Errorf("invalid adaptive coalesce %s thresholds: low %d is greater than high %d", t.name, t.low, t.high)
+		}
+	}
+
+	return nil
+}
+
+// SetCoalesce sets the coalesce config for the given interface name and
+// returns the config actually applied, as drivers may silently clamp values.
 func (e *Ethtool) SetCoalesce(intf string, coalesce Coalesce) (Coalesce, error) {
-	coalesce, err := e.setCoalesce(intf, coalesce)
-	if err != nil {
+	if err := coalesce.Validate(); err != nil {
 		return Coalesce{}, err
 	}
-	return coalesce, nil
+
+	if _, err := e.setCoalesce(intf, coalesce); err != nil {
+		return Coalesce{}, err
+	}
+
+	return e.getCoalesce(intf)
+}
+
+// modifyCoalesce reads the coalesce config of the given interface name, lets
+// f modify it and writes it back.
+func (e *Ethtool) modifyCoalesce(intf string, f func(coalesce *Coalesce)) error {
+	coalesce, err := e.getCoalesce(intf)
+	if err != nil {
+		return err
+	}
+
+	f(&coalesce)
+
+	_, err = e.SetCoalesce(intf, coalesce)
+	return err
+}
+
+// EnableAdaptiveRxCoalesce enables the adaptive RX coalescing of the given
+// interface name, leaving the rest of the coalesce config unchanged. Drivers
+// not supporting adaptive coalescing return ErrNotSupported.
+func (e *Ethtool) EnableAdaptiveRxCoalesce(intf string) error {
+	return e.modifyCoalesce(intf, func(coalesce *Coalesce) {
+		coalesce.UseAdaptiveRxCoalesce = 1
+	})
+}
+
+// DisableAdaptiveRxCoalesce disables the adaptive RX coalescing of the given
+// interface name, leaving the rest of the coalesce config unchanged.
+func (e *Ethtool) DisableAdaptiveRxCoalesce(intf string) error {
+	return e.modifyCoalesce(intf, func(coalesce *Coalesce) {
+		coalesce.UseAdaptiveRxCoalesce = 0
+	})
+}
+
+// EnableAdaptiveTxCoalesce enables the adaptive TX coalescing of the given
+// interface name, leaving the rest of the coalesce config unchanged. Drivers
+// not supporting adaptive coalescing return ErrNotSupported.
+func (e *Ethtool) EnableAdaptiveTxCoalesce(intf string) error {
+	return e.modifyCoalesce(intf, func(coalesce *Coalesce) {
+		coalesce.UseAdaptiveTxCoalesce = 1
+	})
+}
+
+// DisableAdaptiveTxCoalesce disables the adaptive TX coalescing of the given
+// interface name, leaving the rest of the coalesce config unchanged.
+func (e *Ethtool) DisableAdaptiveTxCoalesce(intf string) error {
+	return e.modifyCoalesce(intf, func(coalesce *Coalesce) {
+		coalesce.UseAdaptiveTxCoalesce = 0
+	})
+}
+
+// IsAdaptiveCoalesceSupported reports whether the driver of the given
+// interface name reports adaptive RX and TX coalescing in its coalesce
+// config. The ioctl interface doesn't expose the supported parameters, so a
+// driver supporting adaptive coalescing with it currently disabled is
+// reported as not supporting it.
+func (e *Ethtool) IsAdaptiveCoalesceSupported(intf string) (rx bool, tx bool, err error) {
+	coalesce, err := e.getCoalesce(intf)
+	if err != nil {
+		return false, false, err
+	}
+
+	return coalesce.UseAdaptiveRxCoalesce != 0, coalesce.UseAdaptiveTxCoalesce != 0, nil
 }
 
 // GetTimestampingInformation returns the PTP timestamping information for the given interface name.
@@ -509,27 +1249,59 @@ func (e *Ethtool) GetTimestampingInformation(intf string) (TimestampingInformati
 	return ts, nil
 }
 
+// GetTimestampingInfo returns the timestamping capabilities of the given
+// interface name.
+func (e *Ethtool) GetTimestampingInfo(intf string) (TSInfo, error) {
+	ts, err := e.getTimestampingInformation(intf)
+	if err != nil {
+		return TSInfo{}, err
+	}
+
+	return TSInfo{
+		SoTimestamping: ts.SoTimestamping,
+		PhcIndex:       ts.PhcIndex,
+		TxTypes:        ts.TxTypes,
+		RxFilters:      ts.RxFilters,
+	}, nil
+}
+
 // PermAddr returns permanent address of the given interface name.
+//
+// Deprecated: use PermHardwareAddr instead.
 func (e *Ethtool) PermAddr(intf string) (string, error) {
-	permAddr, err := e.getPermAddr(intf)
+	addr, err := e.PermHardwareAddr(intf)
 	if err != nil {
 		return "", err
 	}
 
-	if permAddr.data[0] == 0 && permAddr.data[1] == 0 &&
-		permAddr.data[2] == 0 && permAddr.data[3] == 0 &&
-		permAddr.data[4] == 0 && permAddr.data[5] == 0 {
+	if addr == nil {
 		return "", nil
 	}
 
-	return fmt.Sprintf("%x:%x:%x:%x:%x:%x",
-		permAddr.data[0:1],
-		permAddr.data[1:2],
-		permAddr.data[2:3],
-		permAddr.data[3:4],
-		permAddr.data[4:5],
-		permAddr.data[5:6],
-	), nil
+	return addr.String(), nil
+}
+
+// PermHardwareAddr returns permanent address of the given interface name,
+// nil if the interface doesn't have any.
+func (e *Ethtool) PermHardwareAddr(intf string) (net.HardwareAddr, error) {
+	permAddr, err := e.getPermAddr(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	size := permAddr.size
+	if size > PERMADDR_LEN {
+		size = PERMADDR_LEN
+	}
+
+	if bytes.Count(permAddr.data[:size], []byte{0}) == int(size) {
+		return nil, nil
+	}
+
+	addr := make(net.HardwareAddr, size)
+	copy(addr, permAddr.data[:size])
+
+	return addr, nil
 }
 
 // GetWakeOnLan returns the WoL config for the given interface name.
@@ -538,7 +1310,7 @@ func (e *Ethtool) GetWakeOnLan(intf string) (WakeOnLan, error) {
 		Cmd: ETHTOOL_GWOL,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&wol))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&wol)); err != nil {
 		return WakeOnLan{}, err
 	}
 
@@ -550,37 +1322,139 @@ func (e *Ethtool) GetWakeOnLan(intf string) (WakeOnLan, error) {
 func (e *Ethtool) SetWakeOnLan(intf string, wol WakeOnLan) (WakeOnLan, error) {
 	wol.Cmd = ETHTOOL_SWOL
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&wol))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&wol)); err != nil {
 		return WakeOnLan{}, err
 	}
 
 	return wol, nil
 }
 
-func (e *Ethtool) ioctl(intf string, data uintptr) error {
+// GetWOL returns the Wake-on-LAN config of the given interface name.
+func (e *Ethtool) GetWOL(intf string) (WOL, error) {
+	wol := WakeOnLan{
+		Cmd: ETHTOOL_GWOL,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&wol)); err != nil {
+		return WOL{}, err
+	}
+
+	return WOL{
+		Supported: wol.Supported,
+		Active:    wol.Opts,
+		SoPass:    wol.sopass,
+	}, nil
+}
+
+// SetWOL sets the Wake-on-LAN config of the given interface name.
+func (e *Ethtool) SetWOL(intf string, wol WOL) error {
+	x := WakeOnLan{
+		Cmd:    ETHTOOL_SWOL,
+		Opts:   wol.Active,
+		sopass: wol.SoPass,
+	}
+
+	return e.ioctl(intf, unsafe.Pointer(&x))
+}
+
+func (e *Ethtool) ioctl(intf string, data unsafe.Pointer) error {
 	var name [IFNAMSIZ]byte
 	copy(name[:], []byte(intf))
 
 	ifr := ifreq{
 		ifr_name: name,
-		ifr_data: data,
+		ifr_data: uintptr(data),
 	}
 
-	_, _, ep := unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd), SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
-	if ep != 0 {
-		return ep
+	// every ethtool structure starts with the command
+	cmd := *(*uint32)(data)
+
+	err := e.rawIoctl(SIOCETHTOOL, unsafe.Pointer(&ifr))
+	// the data is only referenced by the uintptr of the request
+	runtime.KeepAlive(data)
+	if err != nil {
+		return fmt.Errorf("ethtool %s on %q: %w", ethtoolCmdName(cmd), intf, err)
 	}
 
 	return nil
 }
 
-func (e *Ethtool) getDriverInfo(intf string) (ethtoolDrvInfo, error) {
-	drvinfo := ethtoolDrvInfo{
-		cmd: ETHTOOL_GDRVINFO,
-	}
-
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&drvinfo))); err != nil {
-		return ethtoolDrvInfo{}, err
+// rawIoctl issues the given socket ioctl request, retried as configured by
+// SetRetry, and maps the returned errno to the sentinel errors.
+func (e *Ethtool) rawIoctl(req uintptr, ifr unsafe.Pointer) error {
+	err := retryTransient(e.retryAttempts, e.retryDelay, func() error {
+		_, _, ep := unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd), req, uintptr(ifr))
+		if ep != 0 {
+			return ep
+		}
+		return nil
+	})
+	if err != nil {
+		return mapErrno(err)
+	}
+
+	return nil
+}
+
+// SetRetry makes the ethtool operations of the handler retried up to
+// attempts times, waiting delay between attempts, when failing with EINTR
+// or EAGAIN. Operations are not retried by default. It must not be called
+// concurrently with operations of the handler.
+func (e *Ethtool) SetRetry(attempts int, delay time.Duration) {
+	e.retryAttempts = attempts
+	e.retryDelay = delay
+}
+
+// retryTransient runs f, running it again up to attempts times while it
+// fails with EINTR or EAGAIN.
+func retryTransient(attempts int, delay time.Duration, f func() error) error {
+	err := f()
+	for i := 0; i < attempts && (errors.Is(err, unix.EINTR) || errors.Is(err, unix.EAGAIN)); i++ {
+		time.Sleep(delay)
+		err = f()
+	}
+	return err
+}
+
+// GetMTU returns the MTU of the given interface name. This is not an
+// ethtool operation but is provided as a convenience, using SIOCGIFMTU.
+func (e *Ethtool) GetMTU(intf string) (uint32, error) {
+	var ifr ifreqMTU
+	copy(ifr.ifr_name[:], []byte(intf))
+
+	if err := e.rawIoctl(unix.SIOCGIFMTU, unsafe.Pointer(&ifr)); err != nil {
+		return 0, fmt.Errorf("ethtool SIOCGIFMTU on %q: %w", intf, err)
+	}
+
+	return uint32(ifr.ifr_mtu), nil
+}
+
+// SetMTU sets the MTU of the given interface name. This is not an ethtool
+// operation but is provided as a convenience, using SIOCSIFMTU.
+func (e *Ethtool) SetMTU(intf string, mtu uint32) error {
+	if mtu < MIN_MTU || mtu > MAX_MTU {
+		return fmt.Errorf("invalid mtu %d, expected a value between %d and %d", mtu, MIN_MTU, MAX_MTU)
+	}
+
+	ifr := ifreqMTU{
+		ifr_mtu: int32(mtu),
+	}
+	copy(ifr.ifr_name[:], []byte(intf))
+
+	if err := e.rawIoctl(unix.SIOCSIFMTU, unsafe.Pointer(&ifr)); err != nil {
+		return fmt.Errorf("ethtool SIOCSIFMTU on %q: %w", intf, err)
+	}
+
+	return nil
+}
+
+func (e *Ethtool) getDriverInfo(intf string) (ethtoolDrvInfo, error) {
+	drvinfo := ethtoolDrvInfo{
+		cmd: ETHTOOL_GDRVINFO,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&drvinfo)); err != nil {
+		return ethtoolDrvInfo{}, err
 	}
 
 	return drvinfo, nil
@@ -591,7 +1465,7 @@ func (e *Ethtool) getChannels(intf string) (Channels, error) {
 		Cmd: ETHTOOL_GCHANNELS,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&channels))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&channels)); err != nil {
 		return Channels{}, err
 	}
 
@@ -601,7 +1475,7 @@ func (e *Ethtool) getChannels(intf string) (Channels, error) {
 func (e *Ethtool) setChannels(intf string, channels Channels) (Channels, error) {
 	channels.Cmd = ETHTOOL_SCHANNELS
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&channels))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&channels)); err != nil {
 		return Channels{}, err
 	}
 
@@ -613,7 +1487,7 @@ func (e *Ethtool) getCoalesce(intf string) (Coalesce, error) {
 		Cmd: ETHTOOL_GCOALESCE,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&coalesce))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&coalesce)); err != nil {
 		return Coalesce{}, err
 	}
 
@@ -623,7 +1497,7 @@ func (e *Ethtool) getCoalesce(intf string) (Coalesce, error) {
 func (e *Ethtool) setCoalesce(intf string, coalesce Coalesce) (Coalesce, error) {
 	coalesce.Cmd = ETHTOOL_SCOALESCE
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&coalesce))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&coalesce)); err != nil {
 		return Coalesce{}, err
 	}
 
@@ -635,7 +1509,7 @@ func (e *Ethtool) getTimestampingInformation(intf string) (TimestampingInformati
 		Cmd: ETHTOOL_GET_TS_INFO,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&ts))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&ts)); err != nil {
 		return TimestampingInformation{}, err
 	}
 
@@ -648,46 +1522,85 @@ func (e *Ethtool) getPermAddr(intf string) (ethtoolPermAddr, error) {
 		size: PERMADDR_LEN,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&permAddr))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&permAddr)); err != nil {
 		return ethtoolPermAddr{}, err
 	}
 
 	return permAddr, nil
 }
 
-func (e *Ethtool) getModuleEeprom(intf string) (ethtoolEeprom, ethtoolModInfo, error) {
+func (e *Ethtool) getModuleInfo(intf string) (ethtoolModInfo, error) {
 	modInfo := ethtoolModInfo{
 		cmd: ETHTOOL_GMODULEINFO,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&modInfo))); err != nil {
-		return ethtoolEeprom{}, ethtoolModInfo{}, err
+	if err := e.ioctl(intf, unsafe.Pointer(&modInfo)); err != nil {
+		return ethtoolModInfo{}, err
 	}
 
-	eeprom := ethtoolEeprom{
-		cmd:    ETHTOOL_GMODULEEEPROM,
-		len:    modInfo.eeprom_len,
-		offset: 0,
-	}
+	return modInfo, nil
+}
 
-	if modInfo.eeprom_len > EEPROM_LEN {
-		return ethtoolEeprom{}, ethtoolModInfo{}, fmt.Errorf("eeprom size: %d is larger than buffer size: %d", modInfo.eeprom_len, EEPROM_LEN)
+func (e *Ethtool) getModuleEeprom(intf string) ([]byte, ethtoolModInfo, error) {
+	modInfo, err := e.getModuleInfo(intf)
+	if err != nil {
+		return nil, ethtoolModInfo{}, err
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&eeprom))); err != nil {
-		return ethtoolEeprom{}, ethtoolModInfo{}, err
+	// the EEPROM size depends on the module, CMIS ones span several pages
+	eeprom, err := e.readModuleEeprom(intf, 0, modInfo.eeprom_len)
+	if err != nil {
+		return nil, ethtoolModInfo{}, err
 	}
 
 	return eeprom, modInfo, nil
 }
 
+// checkEepromRange returns an error when the given range is not within an
+// EEPROM of the given size.
+func checkEepromRange(offset uint32, length uint64, size uint32) error {
+	if uint64(offset)+length > uint64(size) {
+		return fmt.Errorf("invalid EEPROM range: offset %d, length %d, EEPROM length %d", offset, length, size)
+	}
+	return nil
+}
+
+func (e *Ethtool) readModuleEeprom(intf string, offset, length uint32) ([]byte, error) {
+	return e.readEeprom(intf, ETHTOOL_GMODULEEEPROM, offset, length)
+}
+
+// readEeprom reads length bytes at the given offset with ETHTOOL_GEEPROM or
+// ETHTOOL_GMODULEEEPROM.
+func (e *Ethtool) readEeprom(intf string, cmd, offset, length uint32) ([]byte, error) {
+	hdrLen := uint32(unsafe.Sizeof(ethtoolEeprom{}))
+	if length > math.MaxUint32-hdrLen {
+		return nil, fmt.Errorf("invalid eeprom length %d, expected at most %d", length, math.MaxUint32-hdrLen)
+	}
+	buf := make([]byte, hdrLen+length)
+
+	eeprom := (*ethtoolEeprom)(unsafe.Pointer(&buf[0]))
+	eeprom.cmd = cmd
+	eeprom.offset = offset
+	eeprom.len = length
+
+	if err := e.ioctl(intf, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, err
+	}
+
+	if eeprom.len > length {
+		return nil, fmt.Errorf("invalid eeprom length %d, expected at most %d", eeprom.len, length)
+	}
+
+	return buf[hdrLen : hdrLen+eeprom.len], nil
+}
+
 // GetRing retrieves ring parameters of the given interface name.
 func (e *Ethtool) GetRing(intf string) (Ring, error) {
 	ring := Ring{
 		Cmd: ETHTOOL_GRINGPARAM,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&ring))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&ring)); err != nil {
 		return Ring{}, err
 	}
 
@@ -698,20 +1611,117 @@ func (e *Ethtool) GetRing(intf string) (Ring, error) {
 func (e *Ethtool) SetRing(intf string, ring Ring) (Ring, error) {
 	ring.Cmd = ETHTOOL_SRINGPARAM
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&ring))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&ring)); err != nil {
 		return Ring{}, err
 	}
 
 	return ring, nil
 }
 
+// RingParams contains the RX/TX ring sizes of an interface along with their
+// maximum values.
+type RingParams struct {
+	MaxRx          uint32 `json:"max_rx"`
+	MaxRxMini      uint32 `json:"max_rx_mini"`
+	MaxRxJumbo     uint32 `json:"max_rx_jumbo"`
+	MaxTx          uint32 `json:"max_tx"`
+	RxPending      uint32 `json:"rx_pending"`
+	RxMiniPending  uint32 `json:"rx_mini_pending"`
+	RxJumboPending uint32 `json:"rx_jumbo_pending"`
+	TxPending      uint32 `json:"tx_pending"`
+}
+
+func newRingParams(ring Ring) RingParams {
+	return RingParams{
+		MaxRx:          ring.RxMaxPending,
+		MaxRxMini:      ring.RxMiniMaxPending,
+		MaxRxJumbo:     ring.RxJumboMaxPending,
+		MaxTx:          ring.TxMaxPending,
+		RxPending:      ring.RxPending,
+		RxMiniPending:  ring.RxMiniPending,
+		RxJumboPending: ring.RxJumboPending,
+		TxPending:      ring.TxPending,
+	}
+}
+
+// GetRingParams retrieves the ring sizes of the given interface name.
+func (e *Ethtool) GetRingParams(intf string) (RingParams, error) {
+	ring, err := e.GetRing(intf)
+	if err != nil {
+		return RingParams{}, err
+	}
+
+	return newRingParams(ring), nil
+}
+
+// SetRingParams sets the ring sizes of the given interface name and returns
+// the sizes actually applied, as drivers may adjust them. Maximum values are
+// read-only and ignored.
+func (e *Ethtool) SetRingParams(intf string, r RingParams) (RingParams, error) {
+	ring := Ring{
+		RxPending:      r.RxPending,
+		RxMiniPending:  r.RxMiniPending,
+		RxJumboPending: r.RxJumboPending,
+		TxPending:      r.TxPending,
+	}
+
+	if _, err := e.SetRing(intf, ring); err != nil {
+		return RingParams{}, err
+	}
+
+	return e.GetRingParams(intf)
+}
+
+// ValidateAgainstMax checks that the ring sizes of r don't exceed the
+// maximum values of maxParams, all the violations are reported.
+func (r RingParams) ValidateAgainstMax(maxParams RingParams) error {
+	sizes := []struct {
+		name         string
+		pending, max uint32
+	}{
+		{"rx", r.RxPending, maxParams.MaxRx},
+		{"rx-mini", r.RxMiniPending, maxParams.MaxRxMini},
+		{"rx-jumbo", r.RxJumboPending, maxParams.MaxRxJumbo},
+		{"tx", r.TxPending, maxParams.MaxTx},
+	}
+
+	var violations []string
+	for _, size := range sizes {
+		if size.pending > size.max {
+			violations = append(violations, fmt.Sprintf("%s %d exceeds maximum %d", size.name, size.pending, size.max))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("invalid ring sizes: %s", strings.Join(violations, ", "))
+	}
+
+	return nil
+}
+
+// SetRingParamsValidated checks the ring sizes against the maximum values
+// reported by the given interface name before setting them, avoiding the
+// EINVAL returned by drivers for sizes they don't support.
+func (e *Ethtool) SetRingParamsValidated(intf string, r RingParams) (RingParams, error) {
+	current, err := e.GetRingParams(intf)
+	if err != nil {
+		return RingParams{}, err
+	}
+
+	if err := r.ValidateAgainstMax(current); err != nil {
+		return RingParams{}, err
+	}
+
+	return e.SetRingParams(intf, r)
+}
+
 // GetPause retrieves pause parameters of the given interface name.
 func (e *Ethtool) GetPause(intf string) (Pause, error) {
 	pause := Pause{
 		Cmd: ETHTOOL_GPAUSEPARAM,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&pause))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&pause)); err != nil {
 		return Pause{}, err
 	}
 
@@ -722,7 +1732,7 @@ func (e *Ethtool) GetPause(intf string) (Pause, error) {
 func (e *Ethtool) SetPause(intf string, pause Pause) (Pause, error) {
 	pause.Cmd = ETHTOOL_SPAUSEPARAM
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&pause))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&pause)); err != nil {
 		return Pause{}, err
 	}
 
@@ -735,10 +1745,31 @@ func isFeatureBitSet(blocks [MAX_FEATURE_BLOCKS]ethtoolGetFeaturesBlock, index u
 
 // FeatureState contains the state of a feature.
 type FeatureState struct {
-	Available    bool
-	Requested    bool
-	Active       bool
-	NeverChanged bool
+	Available    bool `json:"available"`     // the feature can be changed
+	Requested    bool `json:"requested"`     // the feature has been requested
+	Active       bool `json:"active"`        // the feature is enabled
+	NeverChanged bool `json:"never_changed"` // the feature can never be changed
+}
+
+// String returns the feature state the way ethtool does, fixed features
+// being the ones which can't be changed.
+func (f FeatureState) String() string {
+	s := "off"
+	if f.Active {
+		s = "on"
+	}
+
+	if !f.Available || f.NeverChanged {
+		s += " [fixed]"
+	} else if f.Requested != f.Active {
+		if f.Requested {
+			s += " [requested on]"
+		} else {
+			s += " [requested off]"
+		}
+	}
+
+	return s
 }
 
 func getFeatureStateBits(blocks [MAX_FEATURE_BLOCKS]ethtoolGetFeaturesBlock, index uint) FeatureState {
@@ -762,21 +1793,34 @@ func setFeatureBit(blocks *[MAX_FEATURE_BLOCKS]ethtoolSetFeaturesBlock, index ui
 	}
 }
 
-func (e *Ethtool) getNames(intf string, mask int) (map[string]uint, error) {
+// StringSet maps the strings of an ethtool string set to their index.
+type StringSet map[string]uint
+
+// Sorted returns the strings of the set in alphabetical order.
+func (s StringSet) Sorted() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *Ethtool) getNames(intf string, mask int) (StringSet, error) {
 	ssetInfo := ethtoolSsetInfo{
 		cmd:       ETHTOOL_GSSET_INFO,
 		sset_mask: 1 << mask,
 		data:      [MAX_SSET_INFO]uint32{},
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&ssetInfo))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&ssetInfo)); err != nil {
 		return nil, err
 	}
 
 	/* we only read data on first index because single bit was set in sset_mask(0x10) */
 	length := ssetInfo.data[0]
 	if length == 0 {
-		return map[string]uint{}, nil
+		return StringSet{}, nil
 	} else if length > MAX_GSTRINGS {
 		return nil, fmt.Errorf("ethtool currently doesn't support more than %d entries, received %d", MAX_GSTRINGS, length)
 	}
@@ -788,11 +1832,11 @@ func (e *Ethtool) getNames(intf string, mask int) (map[string]uint, error) {
 		data:       [MAX_GSTRINGS * ETH_GSTRING_LEN]byte{},
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&gstrings))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&gstrings)); err != nil {
 		return nil, err
 	}
 
-	result := make(map[string]uint)
+	result := make(StringSet)
 	for i := 0; i != int(length); i++ {
 		b := gstrings.data[i*ETH_GSTRING_LEN : i*ETH_GSTRING_LEN+ETH_GSTRING_LEN]
 		key := goString(b)
@@ -805,10 +1849,20 @@ func (e *Ethtool) getNames(intf string, mask int) (map[string]uint, error) {
 }
 
 // FeatureNames shows supported features by their name.
-func (e *Ethtool) FeatureNames(intf string) (map[string]uint, error) {
+func (e *Ethtool) FeatureNames(intf string) (StringSet, error) {
 	return e.getNames(intf, ETH_SS_FEATURES)
 }
 
+// FeatureNamesSorted returns the names of the supported features in
+// alphabetical order.
+func (e *Ethtool) FeatureNamesSorted(intf string) ([]string, error) {
+	names, err := e.FeatureNames(intf)
+	if err != nil {
+		return nil, err
+	}
+	return names.Sorted(), nil
+}
+
 // Features retrieves features of the given interface name.
 func (e *Ethtool) Features(intf string) (map[string]bool, error) {
 	names, err := e.FeatureNames(intf)
@@ -826,7 +1880,7 @@ func (e *Ethtool) Features(intf string) (map[string]bool, error) {
 		size: (length + 32 - 1) / 32,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&features))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&features)); err != nil {
 		return nil, err
 	}
 
@@ -856,7 +1910,7 @@ func (e *Ethtool) FeaturesWithState(intf string) (map[string]FeatureState, error
 		size: (length + 32 - 1) / 32,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&features))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&features)); err != nil {
 		return nil, err
 	}
 
@@ -868,6 +1922,34 @@ func (e *Ethtool) FeaturesWithState(intf string) (map[string]FeatureState, error
 	return result, nil
 }
 
+// DrvHasFeature reports whether the given feature is active and available
+// on the given interface name. Both are false if the feature is unknown.
+func (e *Ethtool) DrvHasFeature(intf, featureName string) (bool, bool, error) {
+	features, err := e.FeaturesWithState(intf)
+	if err != nil {
+		return false, false, err
+	}
+
+	state := features[featureName]
+	return state.Active, state.Available, nil
+}
+
+// DrvHasAnyFeature reports for each given feature name whether it is active
+// on the given interface name.
+func (e *Ethtool) DrvHasAnyFeature(intf string, names ...string) (map[string]bool, error) {
+	features, err := e.FeaturesWithState(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(names))
+	for _, name := range names {
+		result[name] = features[name].Active
+	}
+
+	return result, nil
+}
+
 // Change requests a change in the given device's features.
 func (e *Ethtool) Change(intf string, config map[string]bool) error {
 	names, err := e.FeatureNames(intf)
@@ -890,11 +1972,11 @@ func (e *Ethtool) Change(intf string, config map[string]bool) error {
 		}
 	}
 
-	return e.ioctl(intf, uintptr(unsafe.Pointer(&features)))
+	return e.ioctl(intf, unsafe.Pointer(&features))
 }
 
 // PrivFlagsNames shows supported private flags by their name.
-func (e *Ethtool) PrivFlagsNames(intf string) (map[string]uint, error) {
+func (e *Ethtool) PrivFlagsNames(intf string) (StringSet, error) {
 	return e.getNames(intf, ETH_SS_PRIV_FLAGS)
 }
 
@@ -912,7 +1994,7 @@ func (e *Ethtool) PrivFlags(intf string) (map[string]bool, error) {
 
 	var val ethtoolLink
 	val.cmd = ETHTOOL_GPFLAGS
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&val))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&val)); err != nil {
 		return nil, err
 	}
 
@@ -924,6 +2006,43 @@ func (e *Ethtool) PrivFlags(intf string) (map[string]bool, error) {
 	return result, nil
 }
 
+// PrivFlagState contains the state of a private flag along with its index in
+// the private flags bitmask.
+type PrivFlagState struct {
+	Active bool `json:"active"`
+	Index  uint `json:"index"`
+}
+
+// PrivFlagsWithState retrieves private flags of the given interface name,
+// with their index in the private flags bitmask.
+func (e *Ethtool) PrivFlagsWithState(intf string) (map[string]PrivFlagState, error) {
+	names, err := e.PrivFlagsNames(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	length := uint32(len(names))
+	if length == 0 {
+		return map[string]PrivFlagState{}, nil
+	}
+
+	var val ethtoolLink
+	val.cmd = ETHTOOL_GPFLAGS
+	if err := e.ioctl(intf, unsafe.Pointer(&val)); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]PrivFlagState, length)
+	for name, index := range names {
+		result[name] = PrivFlagState{
+			Active: val.data&(1<<index) != 0,
+			Index:  index,
+		}
+	}
+
+	return result, nil
+}
+
 // UpdatePrivFlags requests a change in the given device's private flags.
 func (e *Ethtool) UpdatePrivFlags(intf string, config map[string]bool) error {
 	names, err := e.PrivFlagsNames(intf)
@@ -933,7 +2052,7 @@ func (e *Ethtool) UpdatePrivFlags(intf string, config map[string]bool) error {
 
 	var curr ethtoolLink
 	curr.cmd = ETHTOOL_GPFLAGS
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&curr))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&curr)); err != nil {
 		return err
 	}
 
@@ -952,7 +2071,19 @@ func (e *Ethtool) UpdatePrivFlags(intf string, config map[string]bool) error {
 		}
 	}
 
-	return e.ioctl(intf, uintptr(unsafe.Pointer(&update)))
+	return e.ioctl(intf, unsafe.Pointer(&update))
+}
+
+// GetPrivateFlags retrieves private flags of the given interface name,
+// see PrivFlags.
+func (e *Ethtool) GetPrivateFlags(intf string) (map[string]bool, error) {
+	return e.PrivFlags(intf)
+}
+
+// SetPrivateFlags sets private flags of the given interface name, flags not
+// present in the given map are left unchanged, see UpdatePrivFlags.
+func (e *Ethtool) SetPrivateFlags(intf string, flags map[string]bool) error {
+	return e.UpdatePrivFlags(intf, flags)
 }
 
 // LinkState get the state of a link.
@@ -961,58 +2092,210 @@ func (e *Ethtool) LinkState(intf string) (uint32, error) {
 		cmd: ETHTOOL_GLINK,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&x))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&x)); err != nil {
 		return 0, err
 	}
 
 	return x.data, nil
 }
 
+// GetDeviceFlags retrieves the ETH_FLAG_* device flags of the given interface name.
+func (e *Ethtool) GetDeviceFlags(intf string) (uint32, error) {
+	x := ethtoolValue{
+		cmd: ETHTOOL_GFLAGS,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&x)); err != nil {
+		return 0, err
+	}
+
+	return x.data, nil
+}
+
+// VLANOffloadActive reports whether the RX VLAN offload is active on the
+// given interface name, according to both its features and its device flags.
+func (e *Ethtool) VLANOffloadActive(intf string) (bool, error) {
+	features, err := e.FeaturesWithState(intf)
+	if err != nil {
+		return false, err
+	}
+
+	flags, err := e.GetDeviceFlags(intf)
+	if err != nil {
+		return false, err
+	}
+
+	return features["rx-vlan-hw-parse"].Active && flags&ETH_FLAG_RXVLAN != 0, nil
+}
+
+// Reset resets the components of the given interface name selected by the
+// ETH_RESET_* flags. Depending on the driver, the interface may go down for
+// a while, so this should be used with caution on production systems.
+func (e *Ethtool) Reset(intf string, flags uint32) error {
+	x := ethtoolValue{
+		cmd:  ETHTOOL_RESET,
+		data: flags,
+	}
+
+	return e.ioctl(intf, unsafe.Pointer(&x))
+}
+
+// RestartAutoneg restarts the auto-negotiation of the given interface name.
+func (e *Ethtool) RestartAutoneg(intf string) error {
+	x := ethtoolValue{
+		cmd: ETHTOOL_NWAY_RST,
+	}
+
+	return e.ioctl(intf, unsafe.Pointer(&x))
+}
+
+// PhysID blinks the LED of the given interface name for the given duration
+// in seconds, it blocks until the blinking stops. Following the kernel
+// convention, a duration of 0 blinks until the calling thread receives a
+// signal, and EBUSY is returned while another identification is running on
+// the same interface. Some drivers blink on their own and ignore the
+// duration.
+func (e *Ethtool) PhysID(intf string, duration uint32) error {
+	x := ethtoolValue{
+		cmd:  ETHTOOL_PHYS_ID,
+		data: duration,
+	}
+
+	return e.ioctl(intf, unsafe.Pointer(&x))
+}
+
 // Stats retrieves stats of the given interface name.
 func (e *Ethtool) Stats(intf string) (map[string]uint64, error) {
 	drvinfo := ethtoolDrvInfo{
 		cmd: ETHTOOL_GDRVINFO,
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&drvinfo))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&drvinfo)); err != nil {
 		return nil, err
 	}
 
-	if drvinfo.n_stats*ETH_GSTRING_LEN > MAX_GSTRINGS*ETH_GSTRING_LEN {
-		return nil, fmt.Errorf("ethtool currently doesn't support more than %d entries, received %d", MAX_GSTRINGS, drvinfo.n_stats)
+	return e.stats(intf, drvinfo, nil)
+}
+
+// statsBuffers are the GSTRINGS and GSTATS buffers of rawStats. The kernel
+// ignores the requested lengths and writes as many entries as the driver
+// currently reports, which may have grown since the driver info, so they
+// are sized after MAX_GSTRINGS and pooled to avoid allocating them for
+// every call.
+type statsBuffers struct {
+	strs   []byte
+	values []uint64
+}
+
+var statsBuffersPool = sync.Pool{
+	New: func() interface{} {
+		return &statsBuffers{
+			strs:   make([]byte, unsafe.Sizeof(ethtoolGStringsHdr{})+MAX_GSTRINGS*ETH_GSTRING_LEN),
+			values: make([]uint64, 1+MAX_GSTRINGS),
+		}
+	},
+}
+
+// stats retrieves the stats of the given interface name according to the
+// number of stats reported by the driver info, only the ones whose name is
+// accepted by keep if not nil.
+func (e *Ethtool) stats(intf string, drvinfo ethtoolDrvInfo, keep func(name []byte) bool) (map[string]uint64, error) {
+	result := make(map[string]uint64)
+
+	err := e.rawStats(intf, drvinfo, func(_ int, name []byte, value uint64) {
+		// names are only allocated for the kept stats
+		if len(name) != 0 && (keep == nil || keep(name)) {
+			result[string(name)] = value
+		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	gstrings := ethtoolGStrings{
-		cmd:        ETHTOOL_GSTRINGS,
-		string_set: ETH_SS_STATS,
-		len:        drvinfo.n_stats,
-		data:       [MAX_GSTRINGS * ETH_GSTRING_LEN]byte{},
+	return result, nil
+}
+
+// rawStats calls f for each stat of the given interface name, in the order
+// defined by the driver. The name is only valid during the call of f.
+func (e *Ethtool) rawStats(intf string, drvinfo ethtoolDrvInfo, f func(index int, name []byte, value uint64)) error {
+	// some drivers don't report any stats, nothing to retrieve then
+	if drvinfo.n_stats == 0 {
+		return nil
+	}
+
+	if drvinfo.n_stats > MAX_GSTRINGS {
+		return fmt.Errorf("ethtool currently doesn't support more than %d entries, received %d", MAX_GSTRINGS, drvinfo.n_stats)
+	}
+
+	bufs := statsBuffersPool.Get().(*statsBuffers)
+	defer statsBuffersPool.Put(bufs)
+
+	strsHdrLen := uint32(unsafe.Sizeof(ethtoolGStringsHdr{}))
+	strsBuf := bufs.strs
+
+	gstrings := (*ethtoolGStringsHdr)(unsafe.Pointer(&strsBuf[0]))
+	gstrings.cmd = ETHTOOL_GSTRINGS
+	gstrings.string_set = ETH_SS_STATS
+	gstrings.len = drvinfo.n_stats
+
+	if err := e.ioctl(intf, unsafe.Pointer(&strsBuf[0])); err != nil {
+		return err
+	}
+
+	values := bufs.values
+
+	stats := (*ethtoolStatsHdr)(unsafe.Pointer(&values[0]))
+	stats.cmd = ETHTOOL_GSTATS
+	stats.n_stats = drvinfo.n_stats
+
+	if err := e.ioctl(intf, unsafe.Pointer(&values[0])); err != nil {
+		return err
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&gstrings))); err != nil {
+	if gstrings.len > MAX_GSTRINGS || stats.n_stats > MAX_GSTRINGS {
+		return fmt.Errorf("ethtool currently doesn't support more than %d entries, received %d", MAX_GSTRINGS, stats.n_stats)
+	}
+	if gstrings.len != stats.n_stats {
+		return fmt.Errorf("number of stats of %s changed from %d to %d while reading them", intf, gstrings.len, stats.n_stats)
+	}
+
+	names := strsBuf[strsHdrLen:]
+	for i := 0; i != int(stats.n_stats); i++ {
+		name := names[i*ETH_GSTRING_LEN : i*ETH_GSTRING_LEN+ETH_GSTRING_LEN]
+		if end := bytes.IndexByte(name, 0); end != -1 {
+			name = name[:end]
+		}
+		f(i, name, values[1+i])
+	}
+
+	return nil
+}
+
+// PhyStats retrieves PHY stats of the given interface name.
+func (e *Ethtool) PhyStats(intf string) (map[string]uint64, error) {
+	names, err := e.getNames(intf, ETH_SS_PHY_STATS)
+	if err != nil {
 		return nil, err
 	}
 
+	if len(names) == 0 {
+		return map[string]uint64{}, nil
+	}
+
 	stats := ethtoolStats{
-		cmd:     ETHTOOL_GSTATS,
-		n_stats: drvinfo.n_stats,
+		cmd:     ETHTOOL_GPHYSTATS,
+		n_stats: uint32(len(names)),
 		data:    [MAX_GSTRINGS]uint64{},
 	}
 
-	if err := e.ioctl(intf, uintptr(unsafe.Pointer(&stats))); err != nil {
+	if err := e.ioctl(intf, unsafe.Pointer(&stats)); err != nil {
 		return nil, err
 	}
 
-	result := make(map[string]uint64)
-	for i := 0; i != int(drvinfo.n_stats); i++ {
-		b := gstrings.data[i*ETH_GSTRING_LEN : i*ETH_GSTRING_LEN+ETH_GSTRING_LEN]
-		strEnd := strings.Index(string(b), "\x00")
-		if strEnd == -1 {
-			strEnd = ETH_GSTRING_LEN
-		}
-		key := string(b[:strEnd])
-		if len(key) != 0 {
-			result[key] = stats.data[i]
+	result := make(map[string]uint64, len(names))
+	for name, index := range names {
+		if index < uint(stats.n_stats) {
+			result[name] = stats.data[index]
 		}
 	}
 
@@ -1026,14 +2309,7 @@ func (e *Ethtool) Close() {
 
 // NewEthtool returns a new ethtool handler
 func NewEthtool() (*Ethtool, error) {
-	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.IPPROTO_IP)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Ethtool{
-		fd: int(fd),
-	}, nil
+	return NewEthtoolWithOptions()
 }
 
 // BusInfo returns bus information of the given interface name.
@@ -1066,7 +2342,40 @@ func Stats(intf string) (map[string]uint64, error) {
 	return e.Stats(intf)
 }
 
+// GetWOL returns the Wake-on-LAN config of the given interface name.
+func GetWOL(intf string) (WOL, error) {
+	e, err := NewEthtool()
+	if err != nil {
+		return WOL{}, err
+	}
+	defer e.Close()
+	return e.GetWOL(intf)
+}
+
+// RestartAutoneg restarts the auto-negotiation of the given interface name.
+func RestartAutoneg(intf string) error {
+	e, err := NewEthtool()
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+	return e.RestartAutoneg(intf)
+}
+
+// PhysID blinks the LED of the given interface name for the given duration
+// in seconds, it blocks until the blinking stops.
+func PhysID(intf string, duration uint32) error {
+	e, err := NewEthtool()
+	if err != nil {
+		return err
+	}
+	defer e.Close()
+	return e.PhysID(intf, duration)
+}
+
 // PermAddr returns permanent address of the given interface name.
+//
+// Deprecated: use PermHardwareAddr instead.
 func PermAddr(intf string) (string, error) {
 	e, err := NewEthtool()
 	if err != nil {
@@ -1076,6 +2385,17 @@ func PermAddr(intf string) (string, error) {
 	return e.PermAddr(intf)
 }
 
+// PermHardwareAddr returns permanent address of the given interface name,
+// nil if the interface doesn't have any.
+func PermHardwareAddr(intf string) (net.HardwareAddr, error) {
+	e, err := NewEthtool()
+	if err != nil {
+		return nil, err
+	}
+	defer e.Close()
+	return e.PermHardwareAddr(intf)
+}
+
 func supportedSpeeds(mask uint64) (ret []struct {
 	name  string
 	mask  uint64
@@ -1108,3 +2428,114 @@ func SupportedSpeed(mask uint64) uint64 {
 	}
 	return ret
 }
+
+// LinkMode describes a link mode.
+type LinkMode struct {
+	Name      string `json:"name"`
+	Bit       uint32 `json:"bit"`
+	Speed     uint32 `json:"speed"`                // Mbps
+	Duplex    Duplex `json:"duplex"`               // DUPLEX_*
+	MediaType string `json:"media_type,omitempty"` // e.g. "T", "KR4", "CR"
+}
+
+func newLinkMode(name string, bit uint64, speed uint64) LinkMode {
+	mode := LinkMode{
+		Name:   name,
+		Bit:    uint32(bit),
+		Speed:  uint32(speed / 1_000_000),
+		Duplex: DUPLEX_UNKNOWN,
+	}
+
+	media := name
+	if i := strings.Index(media, "base"); i != -1 {
+		media = media[i+len("base"):]
+	}
+	if i := strings.IndexByte(media, '_'); i != -1 {
+		switch media[i+1:] {
+		case "Half":
+			mode.Duplex = DUPLEX_HALF
+		case "Full":
+			mode.Duplex = DUPLEX_FULL
+		}
+		media = media[:i]
+	}
+	mode.MediaType = media
+
+	return mode
+}
+
+func isLinkModeBitSet(v []uint32, bit uint64) bool {
+	return bit/32 < uint64(len(v)) && v[bit/32]&(1<<(bit%32)) != 0
+}
+
+// LinkModes returns the link modes set in the given link mode bitmask.
+func LinkModes(v []uint32) []LinkMode {
+	var ret []LinkMode
+	for _, mode := range supportedCapabilities {
+		if isLinkModeBitSet(v, mode.mask) {
+			ret = append(ret, newLinkMode(mode.name, mode.mask, mode.speed))
+		}
+	}
+	return ret
+}
+
+// LinkSpeedNames returns the names of the link modes set in the given link
+// mode bitmask.
+func LinkSpeedNames(v []uint32) []string {
+	var ret []string
+	for _, mode := range LinkModes(v) {
+		ret = append(ret, mode.Name)
+	}
+	return ret
+}
+
+// LinkSpeedMbps returns the distinct speeds, in Mbps and in ascending
+// order, of the link modes set in the given link mode bitmask.
+func LinkSpeedMbps(linkModes []uint32) []uint32 {
+	seen := make(map[uint32]bool)
+
+	var speeds []uint32
+	for _, mode := range LinkModes(linkModes) {
+		if !seen[mode.Speed] {
+			seen[mode.Speed] = true
+			speeds = append(speeds, mode.Speed)
+		}
+	}
+	sort.Slice(speeds, func(i, j int) bool { return speeds[i] < speeds[j] })
+
+	return speeds
+}
+
+// LinkSpeedNamesToMask returns the link mode bitmask corresponding to the
+// given link mode names, the reverse of LinkSpeedNames.
+func LinkSpeedNamesToMask(names []string) ([]uint32, error) {
+	var maxBit uint64
+	for _, mode := range supportedCapabilities {
+		if mode.mask > maxBit {
+			maxBit = mode.mask
+		}
+	}
+
+	v := make([]uint32, maxBit/32+1)
+
+	var unknown []string
+	for _, name := range names {
+		found := false
+		for _, mode := range supportedCapabilities {
+			if mode.name == name {
+				v[mode.mask/32] |= 1 << (mode.mask % 32)
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown link modes: %s", strings.Join(unknown, ", "))
+	}
+
+	return v, nil
+}
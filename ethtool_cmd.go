@@ -29,30 +29,28 @@ import (
 	"math"
 	"reflect"
 	"unsafe"
-
-	"golang.org/x/sys/unix"
 )
 
 // EthtoolCmd is the Go version of the Linux kerne ethtool_cmd struct
 // see ethtool.c
 type EthtoolCmd struct {
-	Cmd            uint32
-	Supported      uint32
-	Advertising    uint32
-	Speed          uint16
-	Duplex         uint8
-	Port           uint8
-	Phy_address    uint8
-	Transceiver    uint8
-	Autoneg        uint8
-	Mdio_support   uint8
-	Maxtxpkt       uint32
-	Maxrxpkt       uint32
-	Speed_hi       uint16
-	Eth_tp_mdix    uint8
-	Reserved2      uint8
-	Lp_advertising uint32
-	Reserved       [2]uint32
+	Cmd            uint32    `json:"-"`
+	Supported      uint32    `json:"supported"`
+	Advertising    uint32    `json:"advertising"`
+	Speed          uint16    `json:"speed"`
+	Duplex         uint8     `json:"duplex"`
+	Port           uint8     `json:"port"`
+	Phy_address    uint8     `json:"phy_address"`
+	Transceiver    uint8     `json:"transceiver"`
+	Autoneg        uint8     `json:"autoneg"`
+	Mdio_support   uint8     `json:"mdio_support"`
+	Maxtxpkt       uint32    `json:"maxtxpkt"`
+	Maxrxpkt       uint32    `json:"maxrxpkt"`
+	Speed_hi       uint16    `json:"speed_hi"`
+	Eth_tp_mdix    uint8     `json:"eth_tp_mdix"`
+	Reserved2      uint8     `json:"-"`
+	Lp_advertising uint32    `json:"lp_advertising"`
+	Reserved       [2]uint32 `json:"-"`
 }
 
 // CmdGet returns the interface settings in the receiver struct
@@ -112,18 +110,8 @@ func (f *EthtoolCmd) reflect(retv *map[string]uint64) {
 func (e *Ethtool) CmdGet(ecmd *EthtoolCmd, intf string) (uint32, error) {
 	ecmd.Cmd = ETHTOOL_GSET
 
-	var name [IFNAMSIZ]byte
-	copy(name[:], []byte(intf))
-
-	ifr := ifreq{
-		ifr_name: name,
-		ifr_data: uintptr(unsafe.Pointer(ecmd)),
-	}
-
-	_, _, ep := unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd),
-		SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
-	if ep != 0 {
-		return 0, ep
+	if err := e.ioctl(intf, unsafe.Pointer(ecmd)); err != nil {
+		return 0, err
 	}
 
 	var speedval uint32 = (uint32(ecmd.Speed_hi) << 16) |
@@ -140,18 +128,8 @@ func (e *Ethtool) CmdGet(ecmd *EthtoolCmd, intf string) (uint32, error) {
 func (e *Ethtool) CmdSet(ecmd *EthtoolCmd, intf string) (uint32, error) {
 	ecmd.Cmd = ETHTOOL_SSET
 
-	var name [IFNAMSIZ]byte
-	copy(name[:], []byte(intf))
-
-	ifr := ifreq{
-		ifr_name: name,
-		ifr_data: uintptr(unsafe.Pointer(ecmd)),
-	}
-
-	_, _, ep := unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd),
-		SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
-	if ep != 0 {
-		return 0, unix.Errno(ep)
+	if err := e.ioctl(intf, unsafe.Pointer(ecmd)); err != nil {
+		return 0, err
 	}
 
 	var speedval uint32 = (uint32(ecmd.Speed_hi) << 16) |
@@ -169,18 +147,8 @@ func (e *Ethtool) CmdGetMapped(intf string) (map[string]uint64, error) {
 		Cmd: ETHTOOL_GSET,
 	}
 
-	var name [IFNAMSIZ]byte
-	copy(name[:], []byte(intf))
-
-	ifr := ifreq{
-		ifr_name: name,
-		ifr_data: uintptr(unsafe.Pointer(&ecmd)),
-	}
-
-	_, _, ep := unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd),
-		SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
-	if ep != 0 {
-		return nil, ep
+	if err := e.ioctl(intf, unsafe.Pointer(&ecmd)); err != nil {
+		return nil, err
 	}
 
 	result := make(map[string]uint64)
@@ -22,8 +22,14 @@
 package ethtool
 
 import (
+	"errors"
+	"fmt"
 	"net"
+	"os"
+	"os/exec"
 	"testing"
+
+	"golang.org/x/sys/unix"
 )
 
 func TestCmdGet(t *testing.T) {
@@ -66,3 +72,196 @@ func TestCmdGetMapped(t *testing.T) {
 		t.Fatal("Unable to get settings map from any interface of this system.")
 	}
 }
+
+func TestGetLinkPartnerCapabilities(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// we expected to have at least one success
+	success := false
+	for _, intf := range intfs {
+		if _, err := e.GetLinkPartnerCapabilities(intf.Name); err == nil {
+			success = true
+		}
+	}
+
+	if !success {
+		t.Fatal("Unable to get link partner capabilities from any interface of this system.")
+	}
+}
+
+func TestGetLinkSettings(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// we expected to have at least one success
+	success := false
+	for _, intf := range intfs {
+		settings, err := e.GetLinkSettings(intf.Name)
+		if err != nil {
+			continue
+		}
+		success = true
+
+		if len(settings.AdvertisingModes) != 1 {
+			t.Errorf("expected the legacy advertising bitmask for %s, got %v", intf.Name, settings.AdvertisingModes)
+		}
+
+		settings.AdvertisingModes = []uint32{0, 1}
+		if err := e.SetLinkSettings(intf.Name, settings); err == nil {
+			t.Errorf("expected an error for link modes beyond bit 31 on %s", intf.Name)
+		}
+	}
+
+	if !success {
+		t.Fatal("Unable to get link settings from any interface of this system.")
+	}
+}
+
+func TestSetLinkSettingsUnknownSpeed(t *testing.T) {
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	settings := LinkSettings{Speed: SPEED_UNKNOWN, Duplex: DUPLEX_FULL, Autoneg: AUTONEG_DISABLE}
+	if err := e.SetLinkSettings("lo", settings); err == nil {
+		t.Error("expected an error for an unknown speed")
+	}
+}
+
+// newTap creates a tap interface, which supports changing its link
+// settings, removed at the end of the test.
+func newTap(t *testing.T) string {
+	fd, err := unix.Open("/dev/net/tun", unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		t.Skipf("unable to open /dev/net/tun: %s", err)
+	}
+	t.Cleanup(func() { unix.Close(fd) })
+
+	ifr, err := unix.NewIfreq("ethtooltap%d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ifr.SetUint16(unix.IFF_TAP | unix.IFF_NO_PI)
+
+	if err := unix.IoctlIfreq(fd, unix.TUNSETIFF, ifr); err != nil {
+		t.Skipf("unable to create a tap interface: %s", err)
+	}
+
+	return ifr.Name()
+}
+
+// newVeth creates a veth pair removed at the end of the test and returns
+// the name of its first end.
+func newVeth(t *testing.T) string {
+	name := fmt.Sprintf("ethveth%d", os.Getpid()%100000)
+	peer := fmt.Sprintf("ethvethp%d", os.Getpid()%100000)
+
+	if out, err := exec.Command("ip", "link", "add", name, "type", "veth", "peer", "name", peer).CombinedOutput(); err != nil {
+		t.Skipf("unable to create a veth pair: %s: %s", err, out)
+	}
+	t.Cleanup(func() { exec.Command("ip", "link", "del", name).Run() })
+
+	return name
+}
+
+func TestWithVeth(t *testing.T) {
+	intf := newVeth(t)
+
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if name, err := e.DriverName(intf); err != nil || name != "veth" {
+		t.Errorf("unexpected driver name %q: %v", name, err)
+	}
+	if info, err := e.DriverInfo(intf); err != nil || info.Driver != "veth" {
+		t.Errorf("unexpected driver info %+v: %v", info, err)
+	}
+	if stats, err := e.Stats(intf); err != nil || len(stats) == 0 {
+		t.Errorf("unexpected stats %v: %v", stats, err)
+	}
+	if features, err := e.Features(intf); err != nil || len(features) == 0 {
+		t.Errorf("unexpected features %v: %v", features, err)
+	}
+	// the pair is created down
+	if state, err := e.LinkState(intf); err != nil || state != 0 {
+		t.Errorf("unexpected link state %d: %v", state, err)
+	}
+	if channels, err := e.GetChannels(intf); err != nil || channels.MaxRx == 0 || channels.MaxTx == 0 {
+		t.Errorf("unexpected channels %+v: %v", channels, err)
+	}
+}
+
+func TestSetLinkSettingsHelpers(t *testing.T) {
+	intf := newTap(t)
+
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if err := e.SetAutoneg(intf, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetSpeed(intf, SPEED_100); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetDuplex(intf, DUPLEX_HALF); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetPortType(intf, PORT_FIBRE); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := e.GetLinkSettings(intf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if settings.Speed != SPEED_100 || settings.Duplex != DUPLEX_HALF || settings.Port != PORT_FIBRE || settings.Autoneg != AUTONEG_DISABLE {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+
+	if err := e.SetSpeed(intf, SPEED_UNKNOWN); err == nil {
+		t.Error("expected an error for an unknown speed")
+	}
+}
+
+func TestAdaptiveCoalesceUnsupported(t *testing.T) {
+	intf := newTap(t)
+
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if err := e.EnableAdaptiveRxCoalesce(intf); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+	if _, _, err := e.IsAdaptiveCoalesceSupported(intf); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
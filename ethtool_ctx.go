@@ -0,0 +1,133 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"context"
+
+	"golang.org/x/sys/unix"
+)
+
+// withContext runs f with a handler using a duplicate of the socket
+// descriptor of e, until f completes or the given context is done. In the
+// latter case f keeps running in the background, its result being
+// discarded, as an ioctl can't be interrupted. The duplicate is only closed
+// once f completed, so that closing e doesn't close a descriptor still in
+// use, and the *Ctx operations only write to their own buffers, so nothing
+// of the caller is modified once they returned.
+func (e *Ethtool) withContext(ctx context.Context, f func(h *Ethtool) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	h, err := e.dup()
+	if err != nil {
+		return err
+	}
+
+	// buffered so that the goroutine never blocks once the result is
+	// not expected anymore
+	done := make(chan error, 1)
+	go func() {
+		defer h.Close()
+		done <- f(h)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dup returns a new handler using a duplicate of the socket descriptor of
+// the given one.
+func (e *Ethtool) dup() (*Ethtool, error) {
+	fd, err := unix.FcntlInt(uintptr(e.fd), unix.F_DUPFD_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ethtool{
+		fd:            fd,
+		family:        e.family,
+		retryAttempts: e.retryAttempts,
+		retryDelay:    e.retryDelay,
+	}, nil
+}
+
+// StatsCtx retrieves stats of the given interface name, returning early
+// with the context error if the context is done first.
+func (e *Ethtool) StatsCtx(ctx context.Context, intf string) (map[string]uint64, error) {
+	var stats map[string]uint64
+	err := e.withContext(ctx, func(h *Ethtool) (err error) {
+		stats, err = h.Stats(intf)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// FeaturesCtx retrieves features of the given interface name, returning
+// early with the context error if the context is done first.
+func (e *Ethtool) FeaturesCtx(ctx context.Context, intf string) (map[string]bool, error) {
+	var features map[string]bool
+	err := e.withContext(ctx, func(h *Ethtool) (err error) {
+		features, err = h.Features(intf)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// DriverInfoCtx returns driver information of the given interface name,
+// returning early with the context error if the context is done first.
+func (e *Ethtool) DriverInfoCtx(ctx context.Context, intf string) (DrvInfo, error) {
+	var info DrvInfo
+	err := e.withContext(ctx, func(h *Ethtool) (err error) {
+		info, err = h.DriverInfo(intf)
+		return
+	})
+	if err != nil {
+		return DrvInfo{}, err
+	}
+	return info, nil
+}
+
+// LinkStateCtx get the link state of the given interface name, returning
+// early with the context error if the context is done first.
+func (e *Ethtool) LinkStateCtx(ctx context.Context, intf string) (uint32, error) {
+	var state uint32
+	err := e.withContext(ctx, func(h *Ethtool) (err error) {
+		state, err = h.LinkState(intf)
+		return
+	})
+	if err != nil {
+		return 0, err
+	}
+	return state, nil
+}
@@ -0,0 +1,98 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithContext(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	if err := et.withContext(ctx, func(*Ethtool) error { called = true; return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %s, got %v", context.Canceled, err)
+	}
+	if called {
+		t.Error("unexpected call with a done context")
+	}
+
+	expected := errors.New("failure")
+	if err := et.withContext(context.Background(), func(*Ethtool) error { return expected }); err != expected {
+		t.Errorf("expected %s, got %v", expected, err)
+	}
+}
+
+func TestWithContextClose(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	release := make(chan struct{})
+	result := make(chan error, 1)
+	err = et.withContext(ctx, func(h *Ethtool) error {
+		<-release
+		_, err := h.LinkState("lo")
+		result <- err
+		return err
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %s, got %v", context.DeadlineExceeded, err)
+	}
+
+	// the operation still running uses its own descriptor
+	et.Close()
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("unexpected error after closing the handler: %s", err)
+	}
+}
+
+func TestLinkStateCtx(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	state, err := et.LinkStateCtx(context.Background(), "lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != 1 {
+		t.Errorf("expected lo link to be up, got %d", state)
+	}
+}
@@ -21,6 +21,10 @@
 
 package ethtool
 
+import (
+	"golang.org/x/sys/unix"
+)
+
 var supportedCapabilities = []struct {
 	name  string
 	mask  uint64
@@ -28,3 +32,13 @@ var supportedCapabilities = []struct {
 }{
 	// no supported capabilities on darwin
 }
+
+// netlinkStats is not supported on darwin
+func netlinkStats(intf string, group uint32) (map[uint16]uint64, error) {
+	return nil, mapErrno(unix.EOPNOTSUPP)
+}
+
+// inNetNs is not supported on darwin
+func inNetNs(nsFd int, f func() (int, error)) (int, error) {
+	return -1, mapErrno(unix.EOPNOTSUPP)
+}
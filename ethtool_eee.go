@@ -0,0 +1,138 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"unsafe"
+)
+
+// following structure comes from uapi/linux/ethtool.h
+type ethtoolEEE struct {
+	cmd            uint32
+	supported      uint32
+	advertised     uint32
+	lp_advertised  uint32
+	eee_active     uint32
+	eee_enabled    uint32
+	tx_lpi_enabled uint32
+	tx_lpi_timer   uint32
+	reserved       [2]uint32
+}
+
+// EEECapabilities contains the Energy Efficient Ethernet link modes
+// advertised by both ends of a link.
+type EEECapabilities struct {
+	Local       []string `json:"local,omitempty"`        // link modes advertised locally
+	LinkPartner []string `json:"link_partner,omitempty"` // link modes advertised by the link partner
+	Common      []string `json:"common,omitempty"`       // link modes advertised by both ends
+	Enabled     bool     `json:"enabled"`                // EEE is enabled locally
+	Active      bool     `json:"active"`                 // EEE was negotiated, it may be dormant nevertheless
+}
+
+// EEE contains the Energy Efficient Ethernet config of an interface, link
+// modes are bitmasks of the legacy 32 bits link modes.
+type EEE struct {
+	Supported    uint32 `json:"supported"`      // r/o link modes supporting EEE
+	Advertised   uint32 `json:"advertised"`     // link modes advertising EEE
+	LPAdvertised uint32 `json:"lp_advertised"`  // r/o link modes advertised by the link partner
+	Enabled      bool   `json:"enabled"`        // EEE is enabled
+	Active       bool   `json:"active"`         // r/o EEE was negotiated
+	TxLPIEnabled bool   `json:"tx_lpi_enabled"` // Tx low power idle is enabled
+	TxLPITimer   uint32 `json:"tx_lpi_timer"`   // delay in microseconds before entering Tx low power idle
+}
+
+func newEEE(eee ethtoolEEE) EEE {
+	return EEE{
+		Supported:    eee.supported,
+		Advertised:   eee.advertised,
+		LPAdvertised: eee.lp_advertised,
+		Enabled:      eee.eee_enabled != 0,
+		Active:       eee.eee_active != 0,
+		TxLPIEnabled: eee.tx_lpi_enabled != 0,
+		TxLPITimer:   eee.tx_lpi_timer,
+	}
+}
+
+func boolToUint32(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (e *Ethtool) getEEE(intf string) (ethtoolEEE, error) {
+	eee := ethtoolEEE{
+		cmd: ETHTOOL_GEEE,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&eee)); err != nil {
+		return ethtoolEEE{}, err
+	}
+
+	return eee, nil
+}
+
+// GetEEECapabilities retrieves the Energy Efficient Ethernet link modes
+// advertised by the given interface name and its link partner.
+func (e *Ethtool) GetEEECapabilities(intf string) (EEECapabilities, error) {
+	eee, err := e.getEEE(intf)
+	if err != nil {
+		return EEECapabilities{}, err
+	}
+
+	return EEECapabilities{
+		Local:       LinkSpeedNames([]uint32{eee.advertised}),
+		LinkPartner: LinkSpeedNames([]uint32{eee.lp_advertised}),
+		Common:      LinkSpeedNames([]uint32{eee.advertised & eee.lp_advertised}),
+		Enabled:     eee.eee_enabled != 0,
+		Active:      eee.eee_active != 0,
+	}, nil
+}
+
+// GetEEE retrieves the Energy Efficient Ethernet config of the given
+// interface name. ErrNotSupported is returned if the driver doesn't support EEE.
+func (e *Ethtool) GetEEE(intf string) (EEE, error) {
+	eee, err := e.getEEE(intf)
+	if err != nil {
+		return EEE{}, err
+	}
+
+	return newEEE(eee), nil
+}
+
+// SetEEE sets the Energy Efficient Ethernet config of the given interface
+// name and returns the config applied by the driver.
+func (e *Ethtool) SetEEE(intf string, eee EEE) (EEE, error) {
+	x := ethtoolEEE{
+		cmd:            ETHTOOL_SEEE,
+		advertised:     eee.Advertised,
+		eee_enabled:    boolToUint32(eee.Enabled),
+		tx_lpi_enabled: boolToUint32(eee.TxLPIEnabled),
+		tx_lpi_timer:   eee.TxLPITimer,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&x)); err != nil {
+		return EEE{}, err
+	}
+
+	return e.GetEEE(intf)
+}
@@ -0,0 +1,50 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"testing"
+)
+
+func TestNewEEE(t *testing.T) {
+	eee := newEEE(ethtoolEEE{
+		supported:      0x28,
+		advertised:     0x20,
+		lp_advertised:  0x20,
+		eee_active:     1,
+		eee_enabled:    1,
+		tx_lpi_enabled: 0,
+		tx_lpi_timer:   17,
+	})
+
+	expected := EEE{
+		Supported:    0x28,
+		Advertised:   0x20,
+		LPAdvertised: 0x20,
+		Enabled:      true,
+		Active:       true,
+		TxLPITimer:   17,
+	}
+	if eee != expected {
+		t.Errorf("expected %+v, got %+v", expected, eee)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// Errors matching, with errors.Is, the errno of the failed operations, as
+// an alternative to comparing with the errno values.
+var (
+	ErrNotSupported = errors.New("ethtool: operation not supported")
+	ErrPermission   = errors.New("ethtool: permission denied")
+	ErrNoDevice     = errors.New("ethtool: no such device")
+	ErrBusy         = errors.New("ethtool: device busy")
+)
+
+var errnoSentinels = map[unix.Errno]error{
+	unix.EOPNOTSUPP: ErrNotSupported,
+	unix.EPERM:      ErrPermission,
+	unix.EACCES:     ErrPermission,
+	unix.ENODEV:     ErrNoDevice,
+	unix.EBUSY:      ErrBusy,
+}
+
+// errnoError is an errno also matching its sentinel error.
+type errnoError struct {
+	errno    unix.Errno
+	sentinel error
+}
+
+func (e *errnoError) Error() string {
+	return e.errno.Error()
+}
+
+func (e *errnoError) Unwrap() error {
+	return e.errno
+}
+
+func (e *errnoError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// mapErrno returns err matching the sentinel error of its errno, if any,
+// while still matching the errno.
+func mapErrno(err error) error {
+	errno, ok := err.(unix.Errno)
+	if !ok {
+		return err
+	}
+
+	if sentinel, ok := errnoSentinels[errno]; ok {
+		return &errnoError{errno: errno, sentinel: sentinel}
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestMapErrno(t *testing.T) {
+	err := fmt.Errorf("ethtool ETHTOOL_GSET on %q: %w", "eth0", mapErrno(unix.EOPNOTSUPP))
+	if !errors.Is(err, ErrNotSupported) || !errors.Is(err, unix.EOPNOTSUPP) {
+		t.Errorf("expected both ErrNotSupported and EOPNOTSUPP to match %v", err)
+	}
+	if errors.Is(err, ErrBusy) {
+		t.Errorf("unexpected ErrBusy match for %v", err)
+	}
+	if err.Error() != `ethtool ETHTOOL_GSET on "eth0": operation not supported` {
+		t.Errorf("unexpected message %q", err)
+	}
+
+	if err := mapErrno(unix.EINVAL); err != unix.EINVAL {
+		t.Errorf("expected EINVAL to be left unchanged, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// FECMode is a bitmask of ETHTOOL_FEC_* modes.
+type FECMode uint32
+
+// FEC modes, see uapi/linux/ethtool.h
+const (
+	ETHTOOL_FEC_NONE  FECMode = 1 << 0
+	ETHTOOL_FEC_AUTO  FECMode = 1 << 1
+	ETHTOOL_FEC_OFF   FECMode = 1 << 2
+	ETHTOOL_FEC_RS    FECMode = 1 << 3
+	ETHTOOL_FEC_BASER FECMode = 1 << 4
+	ETHTOOL_FEC_LLRS  FECMode = 1 << 5
+)
+
+var fecModeNames = []struct {
+	mode FECMode
+	name string
+}{
+	{ETHTOOL_FEC_NONE, "None"},
+	{ETHTOOL_FEC_AUTO, "Auto"},
+	{ETHTOOL_FEC_OFF, "Off"},
+	{ETHTOOL_FEC_RS, "RS"},
+	{ETHTOOL_FEC_BASER, "BaseR"},
+	{ETHTOOL_FEC_LLRS, "LLRS"},
+}
+
+// following structure comes from uapi/linux/ethtool.h
+type ethtoolFecParam struct {
+	cmd        uint32
+	active_fec uint32
+	fec        uint32
+	reserved   uint32
+}
+
+// FECParam contains the Forward Error Correction config of an interface,
+// both fields are bitmasks of ETHTOOL_FEC_* modes.
+type FECParam struct {
+	ActiveFEC     FECMode `json:"active_fec"`     // r/o FEC mode currently in use
+	ConfiguredFEC FECMode `json:"configured_fec"` // FEC modes allowed by the configuration
+}
+
+// FECModeNames returns the names of the ETHTOOL_FEC_* modes set in the
+// given bitmask.
+func FECModeNames(v FECMode) []string {
+	var names []string
+	for _, mode := range fecModeNames {
+		if v&mode.mode != 0 {
+			names = append(names, mode.name)
+		}
+	}
+	return names
+}
+
+// FECModeFromName returns the ETHTOOL_FEC_* mode of the given name, the
+// comparison is case insensitive.
+func FECModeFromName(s string) (FECMode, error) {
+	for _, mode := range fecModeNames {
+		if strings.EqualFold(s, mode.name) {
+			return mode.mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown FEC mode %q", s)
+}
+
+func (f FECMode) String() string {
+	return strings.Join(FECModeNames(f), " ")
+}
+
+// GetFEC retrieves the Forward Error Correction config of the given
+// interface name.
+func (e *Ethtool) GetFEC(intf string) (FECParam, error) {
+	fec := ethtoolFecParam{
+		cmd: ETHTOOL_GFECPARAM,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&fec)); err != nil {
+		return FECParam{}, err
+	}
+
+	return FECParam{
+		ActiveFEC:     FECMode(fec.active_fec),
+		ConfiguredFEC: FECMode(fec.fec),
+	}, nil
+}
+
+// SetFEC sets the Forward Error Correction modes of the given interface
+// name, only ConfiguredFEC is taken into account.
+func (e *Ethtool) SetFEC(intf string, fec FECParam) error {
+	x := ethtoolFecParam{
+		cmd: ETHTOOL_SFECPARAM,
+		fec: uint32(fec.ConfiguredFEC),
+	}
+
+	return e.ioctl(intf, unsafe.Pointer(&x))
+}
@@ -0,0 +1,55 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFECModeNames(t *testing.T) {
+	names := FECModeNames(ETHTOOL_FEC_AUTO | ETHTOOL_FEC_RS | ETHTOOL_FEC_BASER)
+	if !reflect.DeepEqual(names, []string{"Auto", "RS", "BaseR"}) {
+		t.Errorf("unexpected FEC mode names %v", names)
+	}
+
+	if names := FECModeNames(0); len(names) != 0 {
+		t.Errorf("expected no FEC mode names, got %v", names)
+	}
+}
+
+func TestFECModeFromName(t *testing.T) {
+	for _, mode := range []FECMode{ETHTOOL_FEC_NONE, ETHTOOL_FEC_AUTO, ETHTOOL_FEC_OFF, ETHTOOL_FEC_RS, ETHTOOL_FEC_BASER, ETHTOOL_FEC_LLRS} {
+		got, err := FECModeFromName(mode.String())
+		if err != nil || got != mode {
+			t.Errorf("unexpected FEC mode for %q: %v, %v", mode.String(), got, err)
+		}
+	}
+
+	if mode, err := FECModeFromName("baser"); err != nil || mode != ETHTOOL_FEC_BASER {
+		t.Errorf("unexpected FEC mode %v, %v", mode, err)
+	}
+
+	if _, err := FECModeFromName("unknown"); err == nil {
+		t.Error("expected an error for an unknown FEC mode")
+	}
+}
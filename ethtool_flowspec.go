@@ -0,0 +1,185 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// offsets of the fields of the ethtool_flow_union members used by the
+// flow spec builders, see uapi/linux/ethtool.h
+const (
+	ip4SrcOffset     = 0
+	ip4DstOffset     = 4
+	ip4PsrcOffset    = 8
+	ip4PdstOffset    = 10
+	ip6SrcOffset     = 0
+	ip6DstOffset     = 16
+	ip6PsrcOffset    = 32
+	ip6PdstOffset    = 34
+	etherDstOffset   = 0
+	etherSrcOffset   = 6
+	etherProtoOffset = 12
+)
+
+// NewTCP4FlowSpec returns a flow spec matching TCP over IPv4 packets. Nil
+// addresses and zero ports are not matched.
+func NewTCP4FlowSpec(srcIP, dstIP net.IP, srcPort, dstPort uint16) RxFlowSpec {
+	return NewTCP4FlowSpecMask(srcIP, dstIP, srcPort, dstPort,
+		fullIPMask(srcIP, 32), fullIPMask(dstIP, 32), fullPortMask(srcPort), fullPortMask(dstPort))
+}
+
+// NewTCP4FlowSpecMask returns a flow spec matching TCP over IPv4 packets
+// according to the given masks.
+func NewTCP4FlowSpecMask(srcIP, dstIP net.IP, srcPort, dstPort uint16, srcIPMask, dstIPMask net.IPMask, srcPortMask, dstPortMask uint16) RxFlowSpec {
+	return newIP4FlowSpec(TCP_V4_FLOW, srcIP, dstIP, srcPort, dstPort, srcIPMask, dstIPMask, srcPortMask, dstPortMask)
+}
+
+// NewUDP4FlowSpec returns a flow spec matching UDP over IPv4 packets. Nil
+// addresses and zero ports are not matched.
+func NewUDP4FlowSpec(srcIP, dstIP net.IP, srcPort, dstPort uint16) RxFlowSpec {
+	return NewUDP4FlowSpecMask(srcIP, dstIP, srcPort, dstPort,
+		fullIPMask(srcIP, 32), fullIPMask(dstIP, 32), fullPortMask(srcPort), fullPortMask(dstPort))
+}
+
+// NewUDP4FlowSpecMask returns a flow spec matching UDP over IPv4 packets
+// according to the given masks.
+func NewUDP4FlowSpecMask(srcIP, dstIP net.IP, srcPort, dstPort uint16, srcIPMask, dstIPMask net.IPMask, srcPortMask, dstPortMask uint16) RxFlowSpec {
+	return newIP4FlowSpec(UDP_V4_FLOW, srcIP, dstIP, srcPort, dstPort, srcIPMask, dstIPMask, srcPortMask, dstPortMask)
+}
+
+// NewTCP6FlowSpec returns a flow spec matching TCP over IPv6 packets. Nil
+// addresses and zero ports are not matched.
+func NewTCP6FlowSpec(srcIP, dstIP net.IP, srcPort, dstPort uint16) RxFlowSpec {
+	return NewTCP6FlowSpecMask(srcIP, dstIP, srcPort, dstPort,
+		fullIPMask(srcIP, 128), fullIPMask(dstIP, 128), fullPortMask(srcPort), fullPortMask(dstPort))
+}
+
+// NewTCP6FlowSpecMask returns a flow spec matching TCP over IPv6 packets
+// according to the given masks.
+func NewTCP6FlowSpecMask(srcIP, dstIP net.IP, srcPort, dstPort uint16, srcIPMask, dstIPMask net.IPMask, srcPortMask, dstPortMask uint16) RxFlowSpec {
+	return newIP6FlowSpec(TCP_V6_FLOW, srcIP, dstIP, srcPort, dstPort, srcIPMask, dstIPMask, srcPortMask, dstPortMask)
+}
+
+// NewUDP6FlowSpec returns a flow spec matching UDP over IPv6 packets. Nil
+// addresses and zero ports are not matched.
+func NewUDP6FlowSpec(srcIP, dstIP net.IP, srcPort, dstPort uint16) RxFlowSpec {
+	return NewUDP6FlowSpecMask(srcIP, dstIP, srcPort, dstPort,
+		fullIPMask(srcIP, 128), fullIPMask(dstIP, 128), fullPortMask(srcPort), fullPortMask(dstPort))
+}
+
+// NewUDP6FlowSpecMask returns a flow spec matching UDP over IPv6 packets
+// according to the given masks.
+func NewUDP6FlowSpecMask(srcIP, dstIP net.IP, srcPort, dstPort uint16, srcIPMask, dstIPMask net.IPMask, srcPortMask, dstPortMask uint16) RxFlowSpec {
+	return newIP6FlowSpec(UDP_V6_FLOW, srcIP, dstIP, srcPort, dstPort, srcIPMask, dstIPMask, srcPortMask, dstPortMask)
+}
+
+// NewEtherFlowSpec returns a flow spec matching Ethernet frames. Nil
+// addresses and a zero protocol are not matched.
+func NewEtherFlowSpec(srcMAC, dstMAC net.HardwareAddr, proto uint16) RxFlowSpec {
+	return NewEtherFlowSpecMask(srcMAC, dstMAC, proto, fullMACMask(srcMAC), fullMACMask(dstMAC), fullPortMask(proto))
+}
+
+// NewEtherFlowSpecMask returns a flow spec matching Ethernet frames
+// according to the given masks.
+func NewEtherFlowSpecMask(srcMAC, dstMAC net.HardwareAddr, proto uint16, srcMACMask, dstMACMask net.HardwareAddr, protoMask uint16) RxFlowSpec {
+	spec := RxFlowSpec{
+		FlowType: ETHER_FLOW,
+	}
+
+	copy(spec.Header[etherDstOffset:etherDstOffset+6], dstMAC)
+	copy(spec.Header[etherSrcOffset:etherSrcOffset+6], srcMAC)
+	binary.BigEndian.PutUint16(spec.Header[etherProtoOffset:], proto)
+
+	copy(spec.Mask[etherDstOffset:etherDstOffset+6], dstMACMask)
+	copy(spec.Mask[etherSrcOffset:etherSrcOffset+6], srcMACMask)
+	binary.BigEndian.PutUint16(spec.Mask[etherProtoOffset:], protoMask)
+
+	return spec
+}
+
+func newIP4FlowSpec(flowType uint32, srcIP, dstIP net.IP, srcPort, dstPort uint16, srcIPMask, dstIPMask net.IPMask, srcPortMask, dstPortMask uint16) RxFlowSpec {
+	spec := RxFlowSpec{
+		FlowType: flowType,
+	}
+
+	copy(spec.Header[ip4SrcOffset:ip4SrcOffset+4], srcIP.To4())
+	copy(spec.Header[ip4DstOffset:ip4DstOffset+4], dstIP.To4())
+	binary.BigEndian.PutUint16(spec.Header[ip4PsrcOffset:], srcPort)
+	binary.BigEndian.PutUint16(spec.Header[ip4PdstOffset:], dstPort)
+
+	copy(spec.Mask[ip4SrcOffset:ip4SrcOffset+4], ip4Mask(srcIPMask))
+	copy(spec.Mask[ip4DstOffset:ip4DstOffset+4], ip4Mask(dstIPMask))
+	binary.BigEndian.PutUint16(spec.Mask[ip4PsrcOffset:], srcPortMask)
+	binary.BigEndian.PutUint16(spec.Mask[ip4PdstOffset:], dstPortMask)
+
+	return spec
+}
+
+func newIP6FlowSpec(flowType uint32, srcIP, dstIP net.IP, srcPort, dstPort uint16, srcIPMask, dstIPMask net.IPMask, srcPortMask, dstPortMask uint16) RxFlowSpec {
+	spec := RxFlowSpec{
+		FlowType: flowType,
+	}
+
+	copy(spec.Header[ip6SrcOffset:ip6SrcOffset+16], srcIP.To16())
+	copy(spec.Header[ip6DstOffset:ip6DstOffset+16], dstIP.To16())
+	binary.BigEndian.PutUint16(spec.Header[ip6PsrcOffset:], srcPort)
+	binary.BigEndian.PutUint16(spec.Header[ip6PdstOffset:], dstPort)
+
+	copy(spec.Mask[ip6SrcOffset:ip6SrcOffset+16], srcIPMask)
+	copy(spec.Mask[ip6DstOffset:ip6DstOffset+16], dstIPMask)
+	binary.BigEndian.PutUint16(spec.Mask[ip6PsrcOffset:], srcPortMask)
+	binary.BigEndian.PutUint16(spec.Mask[ip6PdstOffset:], dstPortMask)
+
+	return spec
+}
+
+// ip4Mask returns the 4 bytes form of the given mask, which can be given
+// in its 16 bytes form as well.
+func ip4Mask(mask net.IPMask) net.IPMask {
+	if len(mask) == net.IPv6len {
+		return mask[12:]
+	}
+	return mask
+}
+
+func fullIPMask(ip net.IP, bits int) net.IPMask {
+	if ip == nil {
+		return nil
+	}
+	return net.CIDRMask(bits, bits)
+}
+
+func fullMACMask(mac net.HardwareAddr) net.HardwareAddr {
+	if mac == nil {
+		return nil
+	}
+	return net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+}
+
+func fullPortMask(port uint16) uint16 {
+	if port == 0 {
+		return 0
+	}
+	return 0xffff
+}
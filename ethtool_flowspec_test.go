@@ -0,0 +1,90 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNewTCP4FlowSpec(t *testing.T) {
+	spec := NewTCP4FlowSpec(nil, net.ParseIP("192.168.1.2"), 0, 443)
+
+	if spec.FlowType != TCP_V4_FLOW {
+		t.Errorf("unexpected flow type %d", spec.FlowType)
+	}
+
+	expectedHeader := [12]byte{0, 0, 0, 0, 192, 168, 1, 2, 0, 0, 0x01, 0xbb}
+	if header := spec.Header[:12]; string(header) != string(expectedHeader[:]) {
+		t.Errorf("unexpected header % x", header)
+	}
+
+	expectedMask := [12]byte{0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0, 0, 0xff, 0xff}
+	if mask := spec.Mask[:12]; string(mask) != string(expectedMask[:]) {
+		t.Errorf("unexpected mask % x", mask)
+	}
+}
+
+func TestNewUDP4FlowSpecMask(t *testing.T) {
+	spec := NewUDP4FlowSpecMask(net.ParseIP("10.0.0.0"), nil, 0, 53, net.CIDRMask(8, 32), nil, 0, 0xffff)
+
+	expectedMask := [12]byte{0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff}
+	if mask := spec.Mask[:12]; string(mask) != string(expectedMask[:]) {
+		t.Errorf("unexpected mask % x", mask)
+	}
+}
+
+func TestNewTCP6FlowSpec(t *testing.T) {
+	spec := NewTCP6FlowSpec(net.ParseIP("2001:db8::1"), nil, 8080, 0)
+
+	if spec.FlowType != TCP_V6_FLOW {
+		t.Errorf("unexpected flow type %d", spec.FlowType)
+	}
+
+	if ip := net.IP(spec.Header[0:16]); !ip.Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("unexpected source address %s", ip)
+	}
+	if spec.Header[32] != 0x1f || spec.Header[33] != 0x90 {
+		t.Errorf("unexpected source port % x", spec.Header[32:34])
+	}
+	if ones, bits := net.IPMask(spec.Mask[0:16]).Size(); ones != 128 || bits != 128 {
+		t.Errorf("unexpected source address mask %d/%d", ones, bits)
+	}
+	if ones, _ := net.IPMask(spec.Mask[16:32]).Size(); ones != 0 {
+		t.Errorf("unexpected destination address mask %d", ones)
+	}
+}
+
+func TestNewEtherFlowSpec(t *testing.T) {
+	dst, _ := net.ParseMAC("00:11:22:33:44:55")
+	spec := NewEtherFlowSpec(nil, dst, 0x0806)
+
+	expectedHeader := [14]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0, 0, 0, 0, 0, 0, 0x08, 0x06}
+	if header := spec.Header[:14]; string(header) != string(expectedHeader[:]) {
+		t.Errorf("unexpected header % x", header)
+	}
+
+	expectedMask := [14]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0xff, 0xff}
+	if mask := spec.Mask[:14]; string(mask) != string(expectedMask[:]) {
+		t.Errorf("unexpected mask % x", mask)
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"github.com/safchain/ethtool/flowhash"
+)
+
+// EthtoolHandle is the set of the main operations of an ethtool handler,
+// allowing consumers to substitute a fake implementation in their tests,
+// see the ethtooltest package.
+type EthtoolHandle interface {
+	Stats(intf string) (map[string]uint64, error)
+	Features(intf string) (map[string]bool, error)
+	Change(intf string, config map[string]bool) error
+	DriverInfo(intf string) (DrvInfo, error)
+	GetChannels(intf string) (Channels, error)
+	SetChannels(intf string, channels Channels) (Channels, error)
+	GetCoalesce(intf string) (Coalesce, error)
+	LinkState(intf string) (uint32, error)
+	PermAddr(intf string) (string, error)
+	ModuleEeprom(intf string) ([]byte, error)
+	ModuleEepromHex(intf string) (string, error)
+	GetIndirectTable(intf string) (flowhash.IndirectTable, error)
+	SetIndirectTable(intf string, table flowhash.IndirectTable) error
+	Close()
+}
+
+var _ EthtoolHandle = (*Ethtool)(nil)
@@ -0,0 +1,251 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// maximum number of 32 bits words of a link mode bitmask
+const ETHTOOL_LINK_MODE_MASK_MAX_KERNEL_NU32 = 127
+
+// following structure comes from uapi/linux/ethtool.h, the link_mode_masks
+// field holds the supported, advertising and lp_advertising bitmasks.
+type ethtoolLinkSettings struct {
+	cmd                    uint32
+	speed                  uint32
+	duplex                 uint8
+	port                   uint8
+	phy_address            uint8
+	autoneg                uint8
+	mdio_support           uint8
+	eth_tp_mdix            uint8
+	eth_tp_mdix_ctrl       uint8
+	link_mode_masks_nwords int8
+	transceiver            uint8
+	master_slave_cfg       uint8
+	master_slave_state     uint8
+	rate_matching          uint8
+	reserved               [7]uint32
+	link_mode_masks        [3 * ETHTOOL_LINK_MODE_MASK_MAX_KERNEL_NU32]uint32
+}
+
+// getLinkSettings issues ETHTOOL_GLINKSETTINGS, first negotiating the size
+// of the link mode bitmasks with the kernel.
+func (e *Ethtool) getLinkSettings(intf string) (ethtoolLinkSettings, error) {
+	settings := ethtoolLinkSettings{
+		cmd: ETHTOOL_GLINKSETTINGS,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&settings)); err != nil {
+		return ethtoolLinkSettings{}, err
+	}
+
+	// the kernel answers the handshake with the negated number of words
+	if settings.link_mode_masks_nwords >= 0 {
+		return ethtoolLinkSettings{}, fmt.Errorf("unexpected link mode masks handshake answer: %d", settings.link_mode_masks_nwords)
+	}
+
+	settings = ethtoolLinkSettings{
+		cmd:                    ETHTOOL_GLINKSETTINGS,
+		link_mode_masks_nwords: -settings.link_mode_masks_nwords,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&settings)); err != nil {
+		return ethtoolLinkSettings{}, err
+	}
+
+	return settings, nil
+}
+
+func (s *ethtoolLinkSettings) linkModeMask(index int) []uint32 {
+	nwords := int(s.link_mode_masks_nwords)
+	return append([]uint32(nil), s.link_mode_masks[index*nwords:(index+1)*nwords]...)
+}
+
+func (s *ethtoolLinkSettings) supported() []uint32 {
+	return s.linkModeMask(0)
+}
+
+func (s *ethtoolLinkSettings) lpAdvertising() []uint32 {
+	return s.linkModeMask(2)
+}
+
+// GetSupportedLinkModes returns the names of the link modes supported by the
+// given interface name. The legacy ETHTOOL_GSET bitmask is used unless the
+// link speed exceeds 1G, in which case ETHTOOL_GLINKSETTINGS is used.
+func (e *Ethtool) GetSupportedLinkModes(intf string) ([]string, error) {
+	var ecmd EthtoolCmd
+	speed, err := e.CmdGet(&ecmd, intf)
+	if err != nil {
+		return nil, err
+	}
+
+	if speed > 1000 {
+		settings, err := e.getLinkSettings(intf)
+		if err == nil {
+			return LinkSpeedNames(settings.supported()), nil
+		}
+	}
+
+	return LinkSpeedNames([]uint32{ecmd.Supported}), nil
+}
+
+// GetLinkPartnerCapabilities returns the names of the link modes advertised
+// by the link partner of the given interface name, empty if the link partner
+// isn't known, e.g. when the link is down or auto-negotiation is disabled.
+// Like GetSupportedLinkModes, ETHTOOL_GLINKSETTINGS is only used above 1G.
+func (e *Ethtool) GetLinkPartnerCapabilities(intf string) ([]string, error) {
+	var ecmd EthtoolCmd
+	speed, err := e.CmdGet(&ecmd, intf)
+	if err != nil {
+		return nil, err
+	}
+
+	if speed > 1000 && speed != SPEED_UNKNOWN {
+		settings, err := e.getLinkSettings(intf)
+		if err == nil {
+			return LinkSpeedNames(settings.lpAdvertising()), nil
+		}
+	}
+
+	return LinkSpeedNames([]uint32{ecmd.Lp_advertising}), nil
+}
+
+// LinkSettings contains the link settings of an interface.
+type LinkSettings struct {
+	Speed            uint32   `json:"speed"`                       // Mbps, SPEED_UNKNOWN if unknown
+	Duplex           Duplex   `json:"duplex"`                      // DUPLEX_*
+	Port             Port     `json:"port"`                        // PORT_*
+	Autoneg          uint8    `json:"autoneg"`                     // AUTONEG_*
+	AdvertisingModes []uint32 `json:"advertising_modes,omitempty"` // link mode bitmask, see LinkSpeedNamesToMask
+}
+
+// GetLinkSettings retrieves the link settings of the given interface name
+// with ETHTOOL_GSET.
+func (e *Ethtool) GetLinkSettings(intf string) (LinkSettings, error) {
+	var ecmd EthtoolCmd
+	speed, err := e.CmdGet(&ecmd, intf)
+	if err != nil {
+		return LinkSettings{}, err
+	}
+
+	return LinkSettings{
+		Speed:            speed,
+		Duplex:           Duplex(ecmd.Duplex),
+		Port:             Port(ecmd.Port),
+		Autoneg:          ecmd.Autoneg,
+		AdvertisingModes: []uint32{ecmd.Advertising},
+	}, nil
+}
+
+// SetLinkSettings sets the link settings of the given interface name with
+// ETHTOOL_SSET. The current settings are read first so that the settings
+// not part of LinkSettings are preserved, as well as the advertised link
+// modes when AdvertisingModes is nil. ETHTOOL_SSET only handles the link
+// modes of the first 32 bits of the bitmask.
+func (e *Ethtool) SetLinkSettings(intf string, settings LinkSettings) error {
+	if settings.Speed == SPEED_UNKNOWN {
+		return fmt.Errorf("invalid speed: unknown")
+	}
+
+	for i, word := range settings.AdvertisingModes {
+		if i > 0 && word != 0 {
+			return fmt.Errorf("advertised link modes beyond bit 31 are not supported")
+		}
+	}
+
+	return e.modifyCmd(intf, func(ecmd *EthtoolCmd) {
+		if settings.AdvertisingModes != nil {
+			ecmd.Advertising = 0
+			if len(settings.AdvertisingModes) > 0 {
+				ecmd.Advertising = settings.AdvertisingModes[0]
+			}
+		}
+
+		setCmdSpeed(ecmd, settings.Speed)
+		ecmd.Duplex = uint8(settings.Duplex)
+		ecmd.Port = uint8(settings.Port)
+		ecmd.Autoneg = settings.Autoneg
+	})
+}
+
+// modifyCmd reads the settings of the given interface name with
+// ETHTOOL_GSET, lets f modify them and writes them back with ETHTOOL_SSET.
+func (e *Ethtool) modifyCmd(intf string, f func(ecmd *EthtoolCmd)) error {
+	var ecmd EthtoolCmd
+	if _, err := e.CmdGet(&ecmd, intf); err != nil {
+		return err
+	}
+
+	f(&ecmd)
+
+	_, err := e.CmdSet(&ecmd, intf)
+	return err
+}
+
+func setCmdSpeed(ecmd *EthtoolCmd, speed uint32) {
+	ecmd.Speed = uint16(speed & math.MaxUint16)
+	ecmd.Speed_hi = uint16(speed >> 16)
+}
+
+// SetPortType sets the port type of the given interface name, leaving the
+// other settings unchanged.
+func (e *Ethtool) SetPortType(intf string, port Port) error {
+	return e.modifyCmd(intf, func(ecmd *EthtoolCmd) {
+		ecmd.Port = uint8(port)
+	})
+}
+
+// SetDuplex sets the duplex mode of the given interface name, leaving the
+// other settings unchanged.
+func (e *Ethtool) SetDuplex(intf string, duplex Duplex) error {
+	return e.modifyCmd(intf, func(ecmd *EthtoolCmd) {
+		ecmd.Duplex = uint8(duplex)
+	})
+}
+
+// SetSpeed sets the speed, in Mbps, of the given interface name, leaving the
+// other settings unchanged. Drivers usually ignore the speed while
+// auto-negotiation is enabled, see SetAutoneg.
+func (e *Ethtool) SetSpeed(intf string, speed uint32) error {
+	if speed == SPEED_UNKNOWN {
+		return fmt.Errorf("invalid speed: unknown")
+	}
+
+	return e.modifyCmd(intf, func(ecmd *EthtoolCmd) {
+		setCmdSpeed(ecmd, speed)
+	})
+}
+
+// SetAutoneg enables or disables the auto-negotiation of the given interface
+// name, leaving the other settings unchanged.
+func (e *Ethtool) SetAutoneg(intf string, enable bool) error {
+	return e.modifyCmd(intf, func(ecmd *EthtoolCmd) {
+		ecmd.Autoneg = AUTONEG_DISABLE
+		if enable {
+			ecmd.Autoneg = AUTONEG_ENABLE
+		}
+	})
+}
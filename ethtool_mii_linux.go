@@ -0,0 +1,101 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// following structure comes from uapi/linux/mii.h
+type miiIoctlData struct {
+	phy_id  uint16
+	reg_num uint16
+	val_in  uint16
+	val_out uint16
+}
+
+var miiRequestNames = map[uintptr]string{
+	unix.SIOCGMIIPHY: "SIOCGMIIPHY",
+	unix.SIOCGMIIREG: "SIOCGMIIREG",
+	unix.SIOCSMIIREG: "SIOCSMIIREG",
+}
+
+type ifreqMII struct {
+	ifr_name [IFNAMSIZ]byte
+	ifr_data miiIoctlData
+	pad      [16]byte
+}
+
+func (e *Ethtool) miiIoctl(intf string, req uintptr, data *miiIoctlData) error {
+	ifr := ifreqMII{
+		ifr_data: *data,
+	}
+	copy(ifr.ifr_name[:], []byte(intf))
+
+	if err := e.rawIoctl(req, unsafe.Pointer(&ifr)); err != nil {
+		return fmt.Errorf("ethtool %s on %q: %w", miiRequestNames[req], intf, err)
+	}
+
+	*data = ifr.ifr_data
+	return nil
+}
+
+// MIIPhyAddress returns the address of the PHY of the given interface name,
+// using SIOCGMIIPHY.
+func (e *Ethtool) MIIPhyAddress(intf string) (uint16, error) {
+	var data miiIoctlData
+	if err := e.miiIoctl(intf, unix.SIOCGMIIPHY, &data); err != nil {
+		return 0, err
+	}
+
+	return data.phy_id, nil
+}
+
+// MIIRead reads the given register of the PHY at the given address of the
+// given interface name, using SIOCGMIIREG. It requires CAP_NET_ADMIN.
+func (e *Ethtool) MIIRead(intf string, phy, reg uint16) (uint16, error) {
+	data := miiIoctlData{
+		phy_id:  phy,
+		reg_num: reg,
+	}
+	if err := e.miiIoctl(intf, unix.SIOCGMIIREG, &data); err != nil {
+		return 0, err
+	}
+
+	return data.val_out, nil
+}
+
+// MIIWrite writes the given register of the PHY at the given address of the
+// given interface name, using SIOCSMIIREG. It requires CAP_NET_ADMIN, note
+// that writing a wrong value may disrupt the link.
+func (e *Ethtool) MIIWrite(intf string, phy, reg uint16, val uint16) error {
+	data := miiIoctlData{
+		phy_id:  phy,
+		reg_num: reg,
+		val_in:  val,
+	}
+
+	return e.miiIoctl(intf, unix.SIOCSMIIREG, &data)
+}
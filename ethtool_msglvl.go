@@ -26,11 +26,82 @@
 package ethtool
 
 import (
+	"fmt"
 	"unsafe"
+)
 
-	"golang.org/x/sys/unix"
+// Driver message classes, see include/linux/netdevice.h
+const (
+	NETIF_MSG_DRV       = 0x0001
+	NETIF_MSG_PROBE     = 0x0002
+	NETIF_MSG_LINK      = 0x0004
+	NETIF_MSG_TIMER     = 0x0008
+	NETIF_MSG_IFDOWN    = 0x0010
+	NETIF_MSG_IFUP      = 0x0020
+	NETIF_MSG_RX_ERR    = 0x0040
+	NETIF_MSG_TX_ERR    = 0x0080
+	NETIF_MSG_TX_QUEUED = 0x0100
+	NETIF_MSG_INTR      = 0x0200
+	NETIF_MSG_TX_DONE   = 0x0400
+	NETIF_MSG_RX_STATUS = 0x0800
+	NETIF_MSG_PKTDATA   = 0x1000
+	NETIF_MSG_HW        = 0x2000
+	NETIF_MSG_WOL       = 0x4000
 )
 
+// names of the driver message classes, in bit order, as used by ethtool
+var msgLevelNames = []struct {
+	bit  uint32
+	name string
+}{
+	{NETIF_MSG_DRV, "drv"},
+	{NETIF_MSG_PROBE, "probe"},
+	{NETIF_MSG_LINK, "link"},
+	{NETIF_MSG_TIMER, "timer"},
+	{NETIF_MSG_IFDOWN, "ifdown"},
+	{NETIF_MSG_IFUP, "ifup"},
+	{NETIF_MSG_RX_ERR, "rx_err"},
+	{NETIF_MSG_TX_ERR, "tx_err"},
+	{NETIF_MSG_TX_QUEUED, "tx_queued"},
+	{NETIF_MSG_INTR, "intr"},
+	{NETIF_MSG_TX_DONE, "tx_done"},
+	{NETIF_MSG_RX_STATUS, "rx_status"},
+	{NETIF_MSG_PKTDATA, "pktdata"},
+	{NETIF_MSG_HW, "hw"},
+	{NETIF_MSG_WOL, "wol"},
+}
+
+// MsgLevelNames returns the names of the message classes set in the given
+// message level, in bit order like `ethtool` does. Unknown bits are ignored.
+func MsgLevelNames(v uint32) []string {
+	var names []string
+	for _, class := range msgLevelNames {
+		if v&class.bit != 0 {
+			names = append(names, class.name)
+		}
+	}
+	return names
+}
+
+// MsgLevelBitmask returns the message level made of the given message class
+// names, the reverse of MsgLevelNames.
+func MsgLevelBitmask(names []string) (uint32, error) {
+	var v uint32
+
+NAMES:
+	for _, name := range names {
+		for _, class := range msgLevelNames {
+			if class.name == name {
+				v |= class.bit
+				continue NAMES
+			}
+		}
+		return 0, fmt.Errorf("unknown message class %q", name)
+	}
+
+	return v, nil
+}
+
 type ethtoolValue struct { /* ethtool.c: struct ethtool_value */
 	cmd  uint32
 	data uint32
@@ -42,55 +113,37 @@ func (e *Ethtool) MsglvlGet(intf string) (uint32, error) {
 		cmd: ETHTOOL_GMSGLVL,
 	}
 
-	var name [IFNAMSIZ]byte
-	copy(name[:], []byte(intf))
-
-	ifr := ifreq{
-		ifr_name: name,
-		ifr_data: uintptr(unsafe.Pointer(&edata)),
-	}
-
-	_, _, ep := unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd),
-		SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
-	if ep != 0 {
-		return 0, ep
+	if err := e.ioctl(intf, unsafe.Pointer(&edata)); err != nil {
+		return 0, err
 	}
 
 	return edata.data, nil
 }
 
 // MsglvlSet returns the read-msglvl, post-set-msglvl of the given interface.
+// The post-set-msglvl is read back from the driver, which may mask the
+// NETIF_MSG_* classes it doesn't support.
 func (e *Ethtool) MsglvlSet(intf string, valset uint32) (uint32, uint32, error) {
-	edata := ethtoolValue{
-		cmd: ETHTOOL_GMSGLVL,
+	readval, err := e.MsglvlGet(intf)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	var name [IFNAMSIZ]byte
-	copy(name[:], []byte(intf))
-
-	ifr := ifreq{
-		ifr_name: name,
-		ifr_data: uintptr(unsafe.Pointer(&edata)),
+	edata := ethtoolValue{
+		cmd:  ETHTOOL_SMSGLVL,
+		data: valset,
 	}
 
-	_, _, ep := unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd),
-		SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
-	if ep != 0 {
-		return 0, 0, ep
+	if err := e.ioctl(intf, unsafe.Pointer(&edata)); err != nil {
+		return 0, 0, err
 	}
 
-	readval := edata.data
-
-	edata.cmd = ETHTOOL_SMSGLVL
-	edata.data = valset
-
-	_, _, ep = unix.Syscall(unix.SYS_IOCTL, uintptr(e.fd),
-		SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr)))
-	if ep != 0 {
-		return 0, 0, ep
+	setval, err := e.MsglvlGet(intf)
+	if err != nil {
+		return 0, 0, err
 	}
 
-	return readval, edata.data, nil
+	return readval, setval, nil
 }
 
 // MsglvlGet returns the msglvl of the given interface.
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -57,3 +58,25 @@ func TestMsglvlGet(t *testing.T) {
 		t.Fatal("Unable to get msglvl from any interface of this system.")
 	}
 }
+
+func TestMsgLevelNames(t *testing.T) {
+	v := uint32(NETIF_MSG_WOL | NETIF_MSG_DRV | NETIF_MSG_LINK | 1<<20)
+
+	expected := []string{"drv", "link", "wol"}
+	names := MsgLevelNames(v)
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+
+	bitmask, err := MsgLevelBitmask(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bitmask != v&^(1<<20) {
+		t.Errorf("expected 0x%x, got 0x%x", v&^(1<<20), bitmask)
+	}
+
+	if _, err := MsgLevelBitmask([]string{"drv", "debug"}); err == nil {
+		t.Error("expected an error for an unknown message class")
+	}
+}
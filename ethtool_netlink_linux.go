@@ -0,0 +1,246 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// ethtool netlink stats related constants, see uapi/linux/ethtool_netlink.h
+const (
+	ETHTOOL_A_STATS_HEADER = 2
+	ETHTOOL_A_STATS_GROUPS = 3
+	ETHTOOL_A_STATS_GRP    = 4
+
+	ETHTOOL_A_STATS_GRP_ID    = 2
+	ETHTOOL_A_STATS_GRP_SS_ID = 3
+	ETHTOOL_A_STATS_GRP_STAT  = 4
+)
+
+type netlinkAttr struct {
+	typ  uint16
+	data []byte
+}
+
+func encodeNetlinkAttr(typ uint16, data []byte) []byte {
+	b := make([]byte, unix.NLA_HDRLEN+nlaAlign(len(data)))
+	nativeEndian.PutUint16(b[0:2], uint16(unix.NLA_HDRLEN+len(data)))
+	nativeEndian.PutUint16(b[2:4], typ)
+	copy(b[unix.NLA_HDRLEN:], data)
+	return b
+}
+
+func encodeNetlinkNested(typ uint16, attrs ...[]byte) []byte {
+	var data []byte
+	for _, attr := range attrs {
+		data = append(data, attr...)
+	}
+	return encodeNetlinkAttr(typ|unix.NLA_F_NESTED, data)
+}
+
+func encodeNetlinkUint32(typ uint16, v uint32) []byte {
+	b := make([]byte, 4)
+	nativeEndian.PutUint32(b, v)
+	return encodeNetlinkAttr(typ, b)
+}
+
+func encodeNetlinkString(typ uint16, s string) []byte {
+	return encodeNetlinkAttr(typ, append([]byte(s), 0))
+}
+
+func nlaAlign(l int) int {
+	return (l + unix.NLA_ALIGNTO - 1) & ^(unix.NLA_ALIGNTO - 1)
+}
+
+func parseNetlinkAttrs(b []byte) ([]netlinkAttr, error) {
+	var attrs []netlinkAttr
+	for len(b) >= unix.NLA_HDRLEN {
+		l := int(nativeEndian.Uint16(b[0:2]))
+		if l < unix.NLA_HDRLEN || l > len(b) {
+			return nil, fmt.Errorf("invalid netlink attribute length %d", l)
+		}
+
+		attrs = append(attrs, netlinkAttr{
+			typ:  nativeEndian.Uint16(b[2:4]) &^ (unix.NLA_F_NESTED | unix.NLA_F_NET_BYTEORDER),
+			data: b[unix.NLA_HDRLEN:l],
+		})
+
+		if nlaAlign(l) >= len(b) {
+			break
+		}
+		b = b[nlaAlign(l):]
+	}
+	return attrs, nil
+}
+
+// genetlinkRequest sends a generic netlink request and returns the
+// attributes of each reply message.
+func genetlinkRequest(family uint16, cmd uint8, version uint8, attrs ...[]byte) ([][]netlinkAttr, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	payload := []byte{cmd, version, 0, 0}
+	for _, attr := range attrs {
+		payload = append(payload, attr...)
+	}
+
+	const seq = 1
+	msg := make([]byte, unix.NLMSG_HDRLEN+len(payload))
+	nativeEndian.PutUint32(msg[0:4], uint32(len(msg)))
+	nativeEndian.PutUint16(msg[4:6], family)
+	nativeEndian.PutUint16(msg[6:8], unix.NLM_F_REQUEST|unix.NLM_F_ACK)
+	nativeEndian.PutUint32(msg[8:12], seq)
+	copy(msg[unix.NLMSG_HDRLEN:], payload)
+
+	if err := unix.Sendto(fd, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	var replies [][]netlinkAttr
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		b := buf[:n]
+		for len(b) >= unix.NLMSG_HDRLEN {
+			l := int(nativeEndian.Uint32(b[0:4]))
+			if l < unix.NLMSG_HDRLEN || l > len(b) {
+				return nil, fmt.Errorf("invalid netlink message length %d", l)
+			}
+
+			typ := nativeEndian.Uint16(b[4:6])
+			body := b[unix.NLMSG_HDRLEN:l]
+
+			switch {
+			case nativeEndian.Uint32(b[8:12]) != seq:
+			case typ == unix.NLMSG_ERROR:
+				if len(body) < 4 {
+					return nil, fmt.Errorf("truncated netlink error message")
+				}
+				// a zero error code is the acknowledgment ending the request
+				if errno := int32(nativeEndian.Uint32(body[0:4])); errno != 0 {
+					return nil, unix.Errno(-errno)
+				}
+				return replies, nil
+			case typ == unix.NLMSG_DONE:
+				return replies, nil
+			case typ == family && len(body) >= unix.GENL_HDRLEN:
+				attrs, err := parseNetlinkAttrs(body[unix.GENL_HDRLEN:])
+				if err != nil {
+					return nil, err
+				}
+				replies = append(replies, attrs)
+			}
+
+			if nlaAlign(l) >= len(b) {
+				break
+			}
+			b = b[nlaAlign(l):]
+		}
+	}
+}
+
+// genetlinkFamilyID resolves the identifier of a generic netlink family.
+func genetlinkFamilyID(name string) (uint16, error) {
+	replies, err := genetlinkRequest(unix.GENL_ID_CTRL, unix.CTRL_CMD_GETFAMILY, 1,
+		encodeNetlinkString(unix.CTRL_ATTR_FAMILY_NAME, name))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attrs := range replies {
+		for _, attr := range attrs {
+			if attr.typ == unix.CTRL_ATTR_FAMILY_ID && len(attr.data) >= 2 {
+				return nativeEndian.Uint16(attr.data), nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("generic netlink family %q not found", name)
+}
+
+// netlinkStats retrieves the given ETHTOOL_STATS_* group of standard stats
+// of the given interface name through the ethtool netlink interface. Stats
+// are indexed by their ETHTOOL_A_STATS_* identifier, stats not reported by
+// the driver are omitted.
+func netlinkStats(intf string, group uint32) (map[uint16]uint64, error) {
+	family, err := genetlinkFamilyID(unix.ETHTOOL_GENL_NAME)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := genetlinkRequest(family, unix.ETHTOOL_MSG_STATS_GET, unix.ETHTOOL_GENL_VERSION,
+		encodeNetlinkNested(ETHTOOL_A_STATS_HEADER,
+			encodeNetlinkString(unix.ETHTOOL_A_HEADER_DEV_NAME, intf)),
+		encodeNetlinkNested(ETHTOOL_A_STATS_GROUPS,
+			encodeNetlinkAttr(unix.ETHTOOL_A_BITSET_NOMASK, nil),
+			encodeNetlinkUint32(unix.ETHTOOL_A_BITSET_SIZE, ETHTOOL_STATS_COUNT),
+			encodeNetlinkUint32(unix.ETHTOOL_A_BITSET_VALUE, 1<<group)))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[uint16]uint64)
+	for _, attrs := range replies {
+		for _, attr := range attrs {
+			if attr.typ != ETHTOOL_A_STATS_GRP {
+				continue
+			}
+
+			grp, err := parseNetlinkAttrs(attr.data)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, grpAttr := range grp {
+				if grpAttr.typ != ETHTOOL_A_STATS_GRP_STAT {
+					continue
+				}
+
+				// each stat is a nest holding a single attribute
+				stat, err := parseNetlinkAttrs(grpAttr.data)
+				if err != nil {
+					return nil, err
+				}
+				for _, s := range stat {
+					if len(s.data) == 8 {
+						stats[s.typ] = nativeEndian.Uint64(s.data)
+					}
+				}
+			}
+		}
+	}
+
+	return stats, nil
+}
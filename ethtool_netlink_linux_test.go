@@ -0,0 +1,55 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"testing"
+)
+
+func TestNetlinkAttrs(t *testing.T) {
+	b := append(encodeNetlinkString(1, "eth0"),
+		encodeNetlinkNested(2, encodeNetlinkUint32(3, 42))...)
+
+	attrs, err := parseNetlinkAttrs(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attrs) != 2 || attrs[0].typ != 1 || goString(attrs[0].data) != "eth0" || attrs[1].typ != 2 {
+		t.Fatalf("unexpected attributes %+v", attrs)
+	}
+
+	nested, err := parseNetlinkAttrs(attrs[1].data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nested) != 1 || nested[0].typ != 3 || nativeEndian.Uint32(nested[0].data) != 42 {
+		t.Fatalf("unexpected nested attributes %+v", nested)
+	}
+}
+
+func TestGenetlinkFamilyID(t *testing.T) {
+	if _, err := genetlinkFamilyID("nlctrl"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// enterNetNs switches the calling thread to the network namespace referred
+// to by the given file descriptor. The goroutine stays locked to the thread
+// until the returned function restores the original namespace. If this
+// fails the thread is left locked, so that it terminates with the goroutine
+// instead of being reused in the wrong namespace.
+func enterNetNs(nsFd int) (func() error, error) {
+	// the namespace is a property of the thread
+	runtime.LockOSThread()
+
+	origFd, err := unix.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to open the current network namespace: %w", err)
+	}
+
+	if err := unix.Setns(nsFd, unix.CLONE_NEWNET); err != nil {
+		unix.Close(origFd)
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("failed to enter the network namespace: %w", err)
+	}
+
+	return func() error {
+		defer unix.Close(origFd)
+
+		if err := unix.Setns(origFd, unix.CLONE_NEWNET); err != nil {
+			return fmt.Errorf("failed to restore the network namespace: %w", err)
+		}
+		runtime.UnlockOSThread()
+
+		return nil
+	}, nil
+}
+
+// inNetNs calls f from the network namespace referred to by the given file
+// descriptor. Sockets keep the namespace they have been created in.
+func inNetNs(nsFd int, f func() (int, error)) (int, error) {
+	restore, err := enterNetNs(nsFd)
+	if err != nil {
+		return -1, err
+	}
+
+	fd, fErr := f()
+
+	if err := restore(); err != nil {
+		if fErr == nil {
+			unix.Close(fd)
+		}
+		return -1, err
+	}
+
+	return fd, fErr
+}
+
+// NewEthtoolInNetNs returns a new ethtool handler operating on the
+// interfaces of the network namespace referred to by the given file
+// descriptor, see WithNetNsFd.
+//
+// The namespace is only entered while creating the handler socket, so the
+// handler can be used from any goroutine afterwards. The operations
+// relying on netlink sockets created on demand, like GetIEEEStats or the
+// Watcher, still use the namespace of the calling thread, see EnterNetNs.
+func NewEthtoolInNetNs(nsFd int) (*Ethtool, error) {
+	return NewEthtoolWithOptions(WithNetNsFd(nsFd))
+}
+
+// EnterNetNs switches the calling thread, and the handler, to the network
+// namespace referred to by the given file descriptor until the returned
+// rollback function is called.
+//
+// Unlike NewEthtoolInNetNs, every operation issued from the calling
+// goroutine uses the namespace, including the netlink based ones. However
+// the goroutine is locked to its thread until the rollback, the handler
+// must not be used by other goroutines meanwhile, and the namespace of the
+// whole thread is changed. If the rollback fails the thread is left locked
+// so that it terminates with the goroutine.
+func (e *Ethtool) EnterNetNs(nsFd int) (func() error, error) {
+	restore, err := enterNetNs(nsFd)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := unix.Socket(e.family, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.IPPROTO_IP)
+	if err != nil {
+		if rErr := restore(); rErr != nil {
+			return nil, rErr
+		}
+		return nil, err
+	}
+
+	origFd := e.fd
+	e.fd = fd
+
+	return func() error {
+		e.fd = origFd
+		unix.Close(fd)
+
+		return restore()
+	}, nil
+}
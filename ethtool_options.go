@@ -0,0 +1,86 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+type ethtoolConfig struct {
+	family  int
+	netNsFd int
+}
+
+// EthtoolOption customizes an ethtool handler, see NewEthtoolWithOptions.
+type EthtoolOption func(*ethtoolConfig)
+
+// WithAF_INET6 opens the handler socket in the AF_INET6 family, for hosts
+// without IPv4 support.
+func WithAF_INET6() EthtoolOption {
+	return func(c *ethtoolConfig) {
+		c.family = unix.AF_INET6
+	}
+}
+
+// WithNetNsFd makes the handler operate on the interfaces of the network
+// namespace referred to by the given file descriptor, as opened from
+// /proc/<pid>/ns/net or /var/run/netns/<name>. The descriptor is only used
+// while creating the handler.
+func WithNetNsFd(fd int) EthtoolOption {
+	return func(c *ethtoolConfig) {
+		c.netNsFd = fd
+	}
+}
+
+// NewEthtoolWithOptions returns a new ethtool handler customized by the
+// given options.
+func NewEthtoolWithOptions(opts ...EthtoolOption) (*Ethtool, error) {
+	config := ethtoolConfig{
+		family:  unix.AF_INET,
+		netNsFd: -1,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	socket := func() (int, error) {
+		return unix.Socket(config.family, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.IPPROTO_IP)
+	}
+
+	var (
+		fd  int
+		err error
+	)
+	if config.netNsFd != -1 {
+		fd, err = inNetNs(config.netNsFd, socket)
+	} else {
+		fd, err = socket()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ethtool{
+		fd:     fd,
+		family: config.family,
+	}, nil
+}
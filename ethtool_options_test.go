@@ -0,0 +1,179 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestWithAF_INET6(t *testing.T) {
+	et, err := NewEthtoolWithOptions(WithAF_INET6())
+	if errors.Is(err, unix.EAFNOSUPPORT) {
+		t.Skip("IPv6 not supported")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	if _, err := et.LinkState("lo"); err != nil {
+		t.Error(err)
+	}
+}
+
+// newNetNs returns a file descriptor referring to a new network namespace.
+func newNetNs(t *testing.T) int {
+	ch := make(chan int, 1)
+	go func() {
+		// the thread is not unlocked so that it terminates with the
+		// goroutine instead of being reused in the new namespace
+		runtime.LockOSThread()
+
+		if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+			ch <- -1
+			return
+		}
+
+		fd, err := unix.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			fd = -1
+		}
+		ch <- fd
+	}()
+
+	fd := <-ch
+	if fd == -1 {
+		t.Skip("unable to create a network namespace")
+	}
+	return fd
+}
+
+func TestWithNetNsFd(t *testing.T) {
+	nsFd := newNetNs(t)
+	defer unix.Close(nsFd)
+
+	et, err := NewEthtoolWithOptions(WithNetNsFd(nsFd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	// the loopback interface of a new namespace is down
+	if state, err := et.LinkState("lo"); err != nil || state != 0 {
+		t.Errorf("expected lo to be down in the new namespace, got %d, %v", state, err)
+	}
+
+	// while the current namespace is unchanged
+	cur, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cur.Close()
+
+	if state, err := cur.LinkState("lo"); err != nil || state != 1 {
+		t.Errorf("expected lo to be up in the current namespace, got %d, %v", state, err)
+	}
+}
+
+func TestRetryTransient(t *testing.T) {
+	calls := 0
+	eintr := func() error {
+		calls++
+		return unix.EINTR
+	}
+
+	if err := retryTransient(0, 0, eintr); err != unix.EINTR || calls != 1 {
+		t.Errorf("expected a single call without retries, got %d, %v", calls, err)
+	}
+
+	calls = 0
+	if err := retryTransient(3, time.Millisecond, eintr); err != unix.EINTR || calls != 4 {
+		t.Errorf("expected 4 calls with 3 retries, got %d, %v", calls, err)
+	}
+
+	calls = 0
+	err := retryTransient(3, 0, func() error {
+		calls++
+		if calls == 1 {
+			return unix.EAGAIN
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("expected a success after a retry, got %d, %v", calls, err)
+	}
+
+	calls = 0
+	err = retryTransient(3, 0, func() error {
+		calls++
+		return unix.EOPNOTSUPP
+	})
+	if err != unix.EOPNOTSUPP || calls != 1 {
+		t.Errorf("expected no retry for EOPNOTSUPP, got %d, %v", calls, err)
+	}
+
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	et.SetRetry(2, time.Millisecond)
+	if _, err := et.LinkState("lo"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEnterNetNs(t *testing.T) {
+	nsFd := newNetNs(t)
+	defer unix.Close(nsFd)
+
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	rollback, err := et.EnterNetNs(nsFd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the loopback interface of a new namespace is down
+	if state, err := et.LinkState("lo"); err != nil || state != 0 {
+		t.Errorf("expected lo to be down in the new namespace, got %d, %v", state, err)
+	}
+
+	if err := rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if state, err := et.LinkState("lo"); err != nil || state != 1 {
+		t.Errorf("expected lo to be up after the rollback, got %d, %v", state, err)
+	}
+}
@@ -0,0 +1,436 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/safchain/ethtool/flowhash"
+	"golang.org/x/sys/unix"
+)
+
+// Flow types, see uapi/linux/ethtool.h
+const (
+	TCP_V4_FLOW    = 0x01 /* hash or spec (tcp_ip4_spec) */
+	UDP_V4_FLOW    = 0x02 /* hash or spec (udp_ip4_spec) */
+	SCTP_V4_FLOW   = 0x03 /* hash or spec (sctp_ip4_spec) */
+	AH_ESP_V4_FLOW = 0x04 /* hash only */
+	TCP_V6_FLOW    = 0x05 /* hash or spec (tcp_ip6_spec; nfc only) */
+	UDP_V6_FLOW    = 0x06 /* hash or spec (udp_ip6_spec; nfc only) */
+	SCTP_V6_FLOW   = 0x07 /* hash or spec (sctp_ip6_spec; nfc only) */
+	AH_ESP_V6_FLOW = 0x08 /* hash only */
+	AH_V4_FLOW     = 0x09 /* hash or spec (ah_ip4_spec) */
+	ESP_V4_FLOW    = 0x0a /* hash or spec (esp_ip4_spec) */
+	AH_V6_FLOW     = 0x0b /* hash or spec (ah_ip6_spec; nfc only) */
+	ESP_V6_FLOW    = 0x0c /* hash or spec (esp_ip6_spec; nfc only) */
+	IPV4_USER_FLOW = 0x0d /* spec only (usr_ip4_spec) */
+	IP_USER_FLOW   = IPV4_USER_FLOW
+	IPV6_USER_FLOW = 0x0e /* spec only (usr_ip6_spec; nfc only) */
+	IPV4_FLOW      = 0x10 /* hash only */
+	IPV6_FLOW      = 0x11 /* hash only */
+	ETHER_FLOW     = 0x12 /* spec only (ether_spec) */
+)
+
+// Flow hash fields
+const (
+	RXH_L2DA     = 1 << 1
+	RXH_VLAN     = 1 << 2
+	RXH_L3_PROTO = 1 << 3
+	RXH_IP_SRC   = 1 << 4
+	RXH_IP_DST   = 1 << 5
+	RXH_L4_B_0_1 = 1 << 6 /* src port in case of TCP/UDP/SCTP */
+	RXH_L4_B_2_3 = 1 << 7 /* dst port in case of TCP/UDP/SCTP */
+	RXH_DISCARD  = 1 << 31
+)
+
+// maximum size of the RX flow hash indirection table
+const MAX_RXFH_INDIR_SIZE = 4096
+
+// indirection table size keeping the current table when setting the RSS
+// configuration
+const ETH_RXFH_INDIR_NO_CHANGE = 0xffffffff
+
+// input transformation keeping the current one when setting the RSS
+// configuration
+const RXH_XFRM_NO_CHANGE = 0xff
+
+// number of RSS context identifiers probed by ListRSSContexts
+const rssContextsLimit = 256
+
+var rxFlowHashFieldNames = []struct {
+	mask uint64
+	name string
+}{
+	{RXH_L2DA, "L2DA"},
+	{RXH_VLAN, "VLAN tag"},
+	{RXH_L3_PROTO, "L3 proto"},
+	{RXH_IP_SRC, "IP SA"},
+	{RXH_IP_DST, "IP DA"},
+	{RXH_L4_B_0_1, "L4 bytes 0 & 1 [TCP/UDP src port]"},
+	{RXH_L4_B_2_3, "L4 bytes 2 & 3 [TCP/UDP dst port]"},
+}
+
+// hashed flow types described by ChannelMapper
+var rxFlowHashTypes = []struct {
+	flowType uint32
+	name     string
+}{
+	{TCP_V4_FLOW, "tcp4"},
+	{UDP_V4_FLOW, "udp4"},
+	{SCTP_V4_FLOW, "sctp4"},
+	{AH_ESP_V4_FLOW, "ah4"},
+	{TCP_V6_FLOW, "tcp6"},
+	{UDP_V6_FLOW, "udp6"},
+	{SCTP_V6_FLOW, "sctp6"},
+	{AH_ESP_V6_FLOW, "ah6"},
+}
+
+type ethtoolRxfhIndir struct {
+	cmd        uint32
+	size       uint32
+	ring_index [MAX_RXFH_INDIR_SIZE]uint32
+}
+
+// following structure comes from uapi/linux/ethtool.h, the header is
+// followed by the indirection table and by the hash key.
+type ethtoolRxfh struct {
+	cmd         uint32
+	rss_context uint32
+	indir_size  uint32
+	key_size    uint32
+	hfunc       uint8
+	input_xfrm  uint8
+	rsvd8       [2]uint8
+	rsvd32      uint32
+}
+
+// getRxfh issues ETHTOOL_GRSSH for the given RSS context, first retrieving
+// the sizes of the indirection table and of the key.
+func (e *Ethtool) getRxfh(intf string, rssContext uint32) (*flowhash.RSSContext, error) {
+	rxfh := ethtoolRxfh{
+		cmd:         ETHTOOL_GRSSH,
+		rss_context: rssContext,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&rxfh)); err != nil {
+		return nil, err
+	}
+
+	if rxfh.indir_size > MAX_RXFH_INDIR_SIZE {
+		return nil, fmt.Errorf("indirection table size: %d is larger than buffer size: %d", rxfh.indir_size, MAX_RXFH_INDIR_SIZE)
+	}
+
+	hdrLen := uint32(unsafe.Sizeof(ethtoolRxfh{}))
+	buf := make([]byte, hdrLen+rxfh.indir_size*4+rxfh.key_size)
+
+	hdr := (*ethtoolRxfh)(unsafe.Pointer(&buf[0]))
+	hdr.cmd = ETHTOOL_GRSSH
+	hdr.rss_context = rssContext
+	hdr.indir_size = rxfh.indir_size
+	hdr.key_size = rxfh.key_size
+
+	if err := e.ioctl(intf, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, err
+	}
+
+	ctx := &flowhash.RSSContext{
+		ID:       rssContext,
+		Table:    make(flowhash.IndirectTable, hdr.indir_size),
+		HashFunc: hdr.hfunc,
+	}
+
+	data := buf[hdrLen:]
+	for i := range ctx.Table {
+		ctx.Table[i] = nativeEndian.Uint32(data[i*4:])
+	}
+	if hdr.key_size > 0 {
+		ctx.Key = append([]byte(nil), data[hdr.indir_size*4:hdr.indir_size*4+hdr.key_size]...)
+	}
+
+	return ctx, nil
+}
+
+// ListRSSContexts returns the RSS contexts of the given interface name.
+// Context 0 is the default one, always present and listed first. The
+// additional contexts are found by probing the identifiers up to 256, as
+// removed contexts can leave holes. Only the default context is returned
+// when the driver doesn't support additional contexts.
+func (e *Ethtool) ListRSSContexts(intf string) ([]flowhash.RSSContext, error) {
+	ctx, err := e.getRxfh(intf, 0)
+	if err != nil {
+		return nil, err
+	}
+	contexts := []flowhash.RSSContext{*ctx}
+
+	for id := uint32(1); id < rssContextsLimit; id++ {
+		ctx, err := e.getRxfh(intf, id)
+		switch {
+		case err == nil:
+			contexts = append(contexts, *ctx)
+		case errors.Is(err, unix.ENOENT) || errors.Is(err, unix.EINVAL):
+			// no context with this identifier
+		case errors.Is(err, ErrNotSupported):
+			return contexts, nil
+		default:
+			return nil, err
+		}
+	}
+
+	return contexts, nil
+}
+
+// hashFuncBitmask returns the current hash functions bitmask updated to
+// enable or disable the given function, the bits being the indexes of the
+// ETH_SS_RSS_HASH_FUNCS names.
+func hashFuncBitmask(names StringSet, current uint8, funcName string, enable bool) (uint8, error) {
+	index, ok := names[funcName]
+	if !ok || index >= 8 {
+		return 0, fmt.Errorf("unsupported hash function %q", funcName)
+	}
+
+	if enable {
+		return current | 1<<index, nil
+	}
+
+	hfunc := current &^ (1 << index)
+	if hfunc == 0 && current != 0 {
+		return 0, fmt.Errorf("can't disable %s, the only active hash function", funcName)
+	}
+	return hfunc, nil
+}
+
+// SetHashFunction enables or disables the given RSS hash function, e.g.
+// "toeplitz" or "xor", of the given interface name. The other hash
+// functions, the indirection table and the key are left unchanged.
+// Disabling the only active function is refused, while drivers supporting a
+// single active function may reject enabling a second one.
+func (e *Ethtool) SetHashFunction(intf string, funcName string, enable bool) error {
+	names, err := e.getNames(intf, ETH_SS_RSS_HASH_FUNCS)
+	if err != nil {
+		return err
+	}
+
+	current, err := e.getRxfh(intf, 0)
+	if err != nil {
+		return err
+	}
+
+	hfunc, err := hashFuncBitmask(names, current.HashFunc, funcName, enable)
+	if err != nil {
+		return err
+	}
+
+	rxfh := ethtoolRxfh{
+		cmd:        ETHTOOL_SRSSH,
+		indir_size: ETH_RXFH_INDIR_NO_CHANGE,
+		hfunc:      hfunc,
+		input_xfrm: RXH_XFRM_NO_CHANGE,
+	}
+
+	return e.ioctl(intf, unsafe.Pointer(&rxfh))
+}
+
+// GetRXRingCount returns the number of RX rings available for load balancing
+// on the given interface name.
+func (e *Ethtool) GetRXRingCount(intf string) (uint32, error) {
+	nfc := ethtoolRxnfc{
+		cmd: ETHTOOL_GRXRINGS,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&nfc)); err != nil {
+		return 0, err
+	}
+
+	return uint32(nfc.data), nil
+}
+
+// GetRxFlowHashFields returns the RXH_* fields used to hash the flows of the
+// given flow type on the given interface name.
+func (e *Ethtool) GetRxFlowHashFields(intf string, flowType uint32) (uint64, error) {
+	nfc := ethtoolRxnfc{
+		cmd:       ETHTOOL_GRXFH,
+		flow_type: flowType,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&nfc)); err != nil {
+		return 0, err
+	}
+
+	return nfc.data, nil
+}
+
+// GetIndirectTable returns the RX flow hash indirection table of the given
+// interface name.
+func (e *Ethtool) GetIndirectTable(intf string) (flowhash.IndirectTable, error) {
+	indir := ethtoolRxfhIndir{
+		cmd: ETHTOOL_GRXFHINDIR,
+	}
+
+	// first retrieve the size of the table
+	if err := e.ioctl(intf, unsafe.Pointer(&indir)); err != nil {
+		return nil, err
+	}
+
+	if indir.size > MAX_RXFH_INDIR_SIZE {
+		return nil, fmt.Errorf("indirection table size: %d is larger than buffer size: %d", indir.size, MAX_RXFH_INDIR_SIZE)
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&indir)); err != nil {
+		return nil, err
+	}
+
+	return append(flowhash.IndirectTable(nil), indir.ring_index[:indir.size]...), nil
+}
+
+// SetIndirectTable sets the RX flow hash indirection table of the given
+// interface name. The table must have the size reported by GetIndirectTable
+// and is validated against the number of RX rings before being applied.
+func (e *Ethtool) SetIndirectTable(intf string, table flowhash.IndirectTable) error {
+	if len(table) > MAX_RXFH_INDIR_SIZE {
+		return fmt.Errorf("indirection table size: %d is larger than buffer size: %d", len(table), MAX_RXFH_INDIR_SIZE)
+	}
+
+	ringCount, err := e.GetRXRingCount(intf)
+	if err != nil {
+		return err
+	}
+
+	if err := table.Validate(ringCount); err != nil {
+		return err
+	}
+
+	indir := ethtoolRxfhIndir{
+		cmd:  ETHTOOL_SRXFHINDIR,
+		size: uint32(len(table)),
+	}
+	copy(indir.ring_index[:], table)
+
+	return e.ioctl(intf, unsafe.Pointer(&indir))
+}
+
+// RxFlowHashFieldNames returns the names of the given RXH_* fields.
+func RxFlowHashFieldNames(fields uint64) []string {
+	var names []string
+	for _, field := range rxFlowHashFieldNames {
+		if fields&field.mask != 0 {
+			names = append(names, field.name)
+		}
+	}
+	return names
+}
+
+// ChannelMapper summarizes how the flows received by an interface are spread
+// across its RX channels.
+type ChannelMapper struct {
+	Interface  string
+	RingCount  uint32
+	Table      flowhash.IndirectTable
+	HashFields map[string]uint64 // RXH_* fields by flow type name, e.g. "tcp4"
+}
+
+// ChannelMapper returns the channel mapping of the given interface name.
+// Flow types for which the driver doesn't report hash fields are omitted.
+func (e *Ethtool) ChannelMapper(intf string) (*ChannelMapper, error) {
+	ringCount, err := e.GetRXRingCount(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := e.GetIndirectTable(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]uint64)
+	for _, flow := range rxFlowHashTypes {
+		if hash, err := e.GetRxFlowHashFields(intf, flow.flowType); err == nil {
+			fields[flow.name] = hash
+		}
+	}
+
+	return &ChannelMapper{
+		Interface:  intf,
+		RingCount:  ringCount,
+		Table:      table,
+		HashFields: fields,
+	}, nil
+}
+
+// Describe returns a human readable report of the channel mapping, similar
+// to the output of ethtool -x.
+func (m *ChannelMapper) Describe() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "RX flow hash indirection table for %s with %d RX ring(s):\n", m.Interface, m.RingCount)
+	for i, queue := range m.Table {
+		if i%8 == 0 {
+			if i != 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%5d: ", i)
+		}
+		fmt.Fprintf(&b, " %5d", queue)
+	}
+	if len(m.Table) > 0 {
+		b.WriteString("\n")
+	}
+
+	queues := queueRanges(m.Table)
+	for _, flow := range rxFlowHashTypes {
+		fields, ok := m.HashFields[flow.name]
+		if !ok {
+			continue
+		}
+
+		if fields&RXH_DISCARD != 0 {
+			fmt.Fprintf(&b, "%s flows are discarded\n", flow.name)
+		} else if names := RxFlowHashFieldNames(fields); len(names) == 0 {
+			fmt.Fprintf(&b, "%s flows are not hashed\n", flow.name)
+		} else {
+			fmt.Fprintf(&b, "%s flows hash on %s to queues %s\n", flow.name, strings.Join(names, ", "), queues)
+		}
+	}
+
+	return b.String()
+}
+
+// queueRanges returns the queues referenced by the table as ranges, e.g. 0-3,6
+func queueRanges(table flowhash.IndirectTable) string {
+	var ranges []string
+	report := table.OccupancyReport()
+	for i := 0; i < len(report); {
+		j := i
+		for j+1 < len(report) && report[j+1].Queue == report[j].Queue+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprintf("%d", report[i].Queue))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", report[i].Queue, report[j].Queue))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
@@ -0,0 +1,126 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/safchain/ethtool/flowhash"
+)
+
+func TestChannelMapperDescribe(t *testing.T) {
+	m := ChannelMapper{
+		Interface: "eth0",
+		RingCount: 4,
+		Table:     flowhash.IndirectTable{0, 1, 2, 3, 0, 1, 2, 3, 0, 1},
+		HashFields: map[string]uint64{
+			"tcp4": RXH_IP_SRC | RXH_IP_DST | RXH_L4_B_0_1 | RXH_L4_B_2_3,
+			"udp4": RXH_IP_SRC | RXH_IP_DST,
+			"tcp6": 0,
+		},
+	}
+
+	expected := []string{
+		"RX flow hash indirection table for eth0 with 4 RX ring(s):",
+		"    0:      0     1     2     3     0     1     2     3",
+		"    8:      0     1",
+		"tcp4 flows hash on IP SA, IP DA, L4 bytes 0 & 1 [TCP/UDP src port], L4 bytes 2 & 3 [TCP/UDP dst port] to queues 0-3",
+		"udp4 flows hash on IP SA, IP DA to queues 0-3",
+		"tcp6 flows are not hashed",
+		"",
+	}
+
+	if actual := m.Describe(); actual != strings.Join(expected, "\n") {
+		t.Errorf("unexpected description:\n%s", actual)
+	}
+}
+
+func TestQueueRanges(t *testing.T) {
+	if actual := queueRanges(flowhash.IndirectTable{6, 0, 1, 2, 3, 8, 9}); actual != "0-3,6,8-9" {
+		t.Errorf("unexpected queue ranges %q", actual)
+	}
+}
+
+func TestListRSSContexts(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	for _, intf := range intfs {
+		contexts, err := e.ListRSSContexts(intf.Name)
+		if err != nil {
+			continue
+		}
+
+		if len(contexts) == 0 || contexts[0].ID != 0 {
+			t.Fatalf("expected the default context first on %s, got %+v", intf.Name, contexts)
+		}
+		return
+	}
+
+	t.Skip("no interface supporting RSS")
+}
+
+func TestHashFuncBitmask(t *testing.T) {
+	names := StringSet{"toeplitz": 0, "xor": 1, "crc32": 2}
+
+	tests := []struct {
+		current  uint8
+		funcName string
+		enable   bool
+		expected uint8
+		err      bool
+	}{
+		{0x1, "xor", true, 0x3, false},
+		{0x3, "toeplitz", false, 0x2, false},
+		{0x2, "toeplitz", true, 0x3, false},
+		{0x1, "toeplitz", false, 0, true},
+		{0x2, "xor", false, 0, true},
+		{0x1, "toeplitz", true, 0x1, false},
+		{0x1, "sha1", true, 0, true},
+	}
+
+	for _, test := range tests {
+		hfunc, err := hashFuncBitmask(names, test.current, test.funcName, test.enable)
+		if test.err {
+			if err == nil {
+				t.Errorf("expected an error setting %s to %v from 0x%x", test.funcName, test.enable, test.current)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hfunc != test.expected {
+			t.Errorf("expected 0x%x setting %s to %v from 0x%x, got 0x%x", test.expected, test.funcName, test.enable, test.current, hfunc)
+		}
+	}
+}
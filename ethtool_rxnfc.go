@@ -0,0 +1,336 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// rules fetched by GetNTupleRule are cached for this duration
+const ntupleRuleCacheTTL = time.Second
+
+// Special RX classification rule locations and actions
+const (
+	RX_CLS_FLOW_DISC   = 0xffffffffffffffff // ring cookie dropping the packets
+	RX_CLS_LOC_SPECIAL = 0x80000000         // flag for the special locations
+	RX_CLS_LOC_ANY     = 0xffffffff         // any location chosen by the driver
+	RX_CLS_LOC_FIRST   = 0xfffffffe         // first available location
+	RX_CLS_LOC_LAST    = 0xfffffffd         // last available location
+)
+
+// Flow type flags
+const (
+	FLOW_EXT     = 0x80000000 // HeaderExt and MaskExt are used
+	FLOW_MAC_EXT = 0x40000000 // destination MAC of HeaderExt and MaskExt is used
+	FLOW_RSS     = 0x20000000 // the rule targets an RSS context
+)
+
+// following structures comes from uapi/linux/ethtool.h
+type ethtoolRxFlowSpec struct {
+	flow_type   uint32
+	h_u         [52]byte // union ethtool_flow_union
+	h_ext       [20]byte // struct ethtool_flow_ext
+	m_u         [52]byte
+	m_ext       [20]byte
+	ring_cookie uint64
+	location    uint32
+}
+
+type ethtoolRxnfc struct {
+	cmd       uint32
+	flow_type uint32
+	data      uint64
+	fs        ethtoolRxFlowSpec
+	rule_cnt  uint32
+}
+
+// RxFlowRule is an RX network flow classification rule, see
+// struct ethtool_rx_flow_spec. Header and Mask hold the raw
+// union ethtool_flow_union matching FlowType, HeaderExt and MaskExt
+// the raw struct ethtool_flow_ext.
+type RxFlowRule struct {
+	FlowType   uint32   `json:"flow_type"`
+	Header     [52]byte `json:"header"`
+	HeaderExt  [20]byte `json:"header_ext"`
+	Mask       [52]byte `json:"mask"`
+	MaskExt    [20]byte `json:"mask_ext"`
+	RingCookie uint64   `json:"ring_cookie"`
+	Location   uint32   `json:"location"`
+}
+
+// RxFlowSpec is the match part of an RX network flow classification rule.
+// Header and Mask hold the raw union ethtool_flow_union matching FlowType,
+// HeaderExt and MaskExt the raw struct ethtool_flow_ext. Mask bits set to
+// one are the ones matched.
+type RxFlowSpec struct {
+	FlowType  uint32   `json:"flow_type"`
+	Header    [52]byte `json:"header"`
+	HeaderExt [20]byte `json:"header_ext"`
+	Mask      [52]byte `json:"mask"`
+	MaskExt   [20]byte `json:"mask_ext"`
+}
+
+// RxNfcRule is an RX network flow classification rule, packets matching
+// Spec are steered to the queue given by RingCookie.
+type RxNfcRule struct {
+	Spec       RxFlowSpec `json:"spec"`
+	RingCookie uint64     `json:"ring_cookie"`
+	Location   uint32     `json:"location"`
+}
+
+func newRxNfcRule(fs ethtoolRxFlowSpec) RxNfcRule {
+	return RxNfcRule{
+		Spec: RxFlowSpec{
+			FlowType:  fs.flow_type,
+			Header:    fs.h_u,
+			HeaderExt: fs.h_ext,
+			Mask:      fs.m_u,
+			MaskExt:   fs.m_ext,
+		},
+		RingCookie: fs.ring_cookie,
+		Location:   fs.location,
+	}
+}
+
+func (r RxNfcRule) flowSpec() ethtoolRxFlowSpec {
+	return ethtoolRxFlowSpec{
+		flow_type:   r.Spec.FlowType,
+		h_u:         r.Spec.Header,
+		h_ext:       r.Spec.HeaderExt,
+		m_u:         r.Spec.Mask,
+		m_ext:       r.Spec.MaskExt,
+		ring_cookie: r.RingCookie,
+		location:    r.Location,
+	}
+}
+
+type ntupleCacheKey struct {
+	intf     string
+	location uint32
+}
+
+type ntupleCacheEntry struct {
+	rule    *RxFlowRule
+	fetched time.Time
+}
+
+func newRxFlowRule(fs ethtoolRxFlowSpec) *RxFlowRule {
+	return &RxFlowRule{
+		FlowType:   fs.flow_type,
+		Header:     fs.h_u,
+		HeaderExt:  fs.h_ext,
+		Mask:       fs.m_u,
+		MaskExt:    fs.m_ext,
+		RingCookie: fs.ring_cookie,
+		Location:   fs.location,
+	}
+}
+
+// GetNTupleRule retrieves the RX classification rule at the given location
+// of the given interface name. It returns nil if the location is not in use.
+// Rules are cached for a short time to avoid repeated ioctls.
+func (e *Ethtool) GetNTupleRule(intf string, location uint32) (*RxFlowRule, error) {
+	key := ntupleCacheKey{intf: intf, location: location}
+
+	entry, gen, ok := e.cachedNTupleRule(key)
+	if ok {
+		return copyRxFlowRule(entry.rule), nil
+	}
+
+	nfc := ethtoolRxnfc{
+		cmd: ETHTOOL_GRXCLSRULE,
+		fs: ethtoolRxFlowSpec{
+			location: location,
+		},
+	}
+
+	var rule *RxFlowRule
+	if err := e.ioctl(intf, unsafe.Pointer(&nfc)); err != nil {
+		if !errors.Is(err, unix.ENOENT) {
+			return nil, err
+		}
+	} else {
+		rule = newRxFlowRule(nfc.fs)
+	}
+
+	e.ntupleCacheLock.Lock()
+	// rules added or deleted during the ioctl invalidated the cache, the
+	// retrieved rule may already be outdated
+	if e.ntupleCacheGen == gen {
+		if e.ntupleCache == nil {
+			e.ntupleCache = make(map[ntupleCacheKey]ntupleCacheEntry)
+		}
+		e.ntupleCache[key] = ntupleCacheEntry{rule: rule, fetched: time.Now()}
+	}
+	e.ntupleCacheLock.Unlock()
+
+	return copyRxFlowRule(rule), nil
+}
+
+// cachedNTupleRule returns the cached rule of the given key, if any, along
+// with the generation of the cache, evicting the expired rules.
+func (e *Ethtool) cachedNTupleRule(key ntupleCacheKey) (ntupleCacheEntry, uint64, bool) {
+	e.ntupleCacheLock.Lock()
+	defer e.ntupleCacheLock.Unlock()
+
+	now := time.Now()
+	for k, entry := range e.ntupleCache {
+		if now.Sub(entry.fetched) >= ntupleRuleCacheTTL {
+			delete(e.ntupleCache, k)
+		}
+	}
+
+	entry, ok := e.ntupleCache[key]
+	return entry, e.ntupleCacheGen, ok
+}
+
+func copyRxFlowRule(rule *RxFlowRule) *RxFlowRule {
+	if rule == nil {
+		return nil
+	}
+	r := *rule
+	return &r
+}
+
+// invalidateNTupleCache drops the cached rules of the given interface name.
+func (e *Ethtool) invalidateNTupleCache(intf string) {
+	e.ntupleCacheLock.Lock()
+	defer e.ntupleCacheLock.Unlock()
+
+	e.ntupleCacheGen++
+	for key := range e.ntupleCache {
+		if key.intf == intf {
+			delete(e.ntupleCache, key)
+		}
+	}
+}
+
+// RxNfcRuleCount returns the number of RX classification rules of the given
+// interface name.
+func (e *Ethtool) RxNfcRuleCount(intf string) (uint32, error) {
+	nfc := ethtoolRxnfc{
+		cmd: ETHTOOL_GRXCLSRLCNT,
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&nfc)); err != nil {
+		return 0, err
+	}
+
+	return nfc.rule_cnt, nil
+}
+
+// GetRxNfcRule retrieves the RX classification rule at the given location
+// of the given interface name.
+func (e *Ethtool) GetRxNfcRule(intf string, location uint32) (RxNfcRule, error) {
+	nfc := ethtoolRxnfc{
+		cmd: ETHTOOL_GRXCLSRULE,
+		fs: ethtoolRxFlowSpec{
+			location: location,
+		},
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&nfc)); err != nil {
+		return RxNfcRule{}, err
+	}
+
+	return newRxNfcRule(nfc.fs), nil
+}
+
+// ListRxNfcRules retrieves all the RX classification rules of the given
+// interface name.
+func (e *Ethtool) ListRxNfcRules(intf string) ([]RxNfcRule, error) {
+	count, err := e.RxNfcRuleCount(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		return []RxNfcRule{}, nil
+	}
+
+	// the rule locations follow the rule count in memory
+	locsOffset := int(unsafe.Offsetof(ethtoolRxnfc{}.rule_cnt)+4) / 4
+	buf := make([]uint32, locsOffset+int(count)+1)
+
+	nfc := (*ethtoolRxnfc)(unsafe.Pointer(&buf[0]))
+	nfc.cmd = ETHTOOL_GRXCLSRLALL
+	nfc.rule_cnt = count
+
+	if err := e.ioctl(intf, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, err
+	}
+
+	if nfc.rule_cnt > count {
+		return nil, fmt.Errorf("invalid rule count %d, expected at most %d", nfc.rule_cnt, count)
+	}
+
+	locs := buf[locsOffset : locsOffset+int(nfc.rule_cnt)]
+	rules := make([]RxNfcRule, 0, len(locs))
+	for _, location := range locs {
+		rule, err := e.GetRxNfcRule(intf, location)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// AddRxNfcRule inserts the given RX classification rule on the given
+// interface name. The rule location can be RX_CLS_LOC_ANY to let the driver
+// choose it, the location actually used is returned.
+func (e *Ethtool) AddRxNfcRule(intf string, rule RxNfcRule) (uint32, error) {
+	nfc := ethtoolRxnfc{
+		cmd:       ETHTOOL_SRXCLSRLINS,
+		flow_type: rule.Spec.FlowType,
+		fs:        rule.flowSpec(),
+	}
+
+	defer e.invalidateNTupleCache(intf)
+
+	if err := e.ioctl(intf, unsafe.Pointer(&nfc)); err != nil {
+		return 0, err
+	}
+
+	return nfc.fs.location, nil
+}
+
+// DeleteRxNfcRule deletes the RX classification rule at the given location
+// of the given interface name.
+func (e *Ethtool) DeleteRxNfcRule(intf string, location uint32) error {
+	nfc := ethtoolRxnfc{
+		cmd: ETHTOOL_SRXCLSRLDEL,
+		fs: ethtoolRxFlowSpec{
+			location: location,
+		},
+	}
+
+	defer e.invalidateNTupleCache(intf)
+
+	return e.ioctl(intf, unsafe.Pointer(&nfc))
+}
@@ -0,0 +1,94 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestRxnfcLayout(t *testing.T) {
+	// sizes and offsets from uapi/linux/ethtool.h with 8 bytes aligned uint64s
+	if unsafe.Alignof(uint64(0)) != 8 {
+		t.Skip("uint64 not 8 bytes aligned")
+	}
+
+	if size := unsafe.Sizeof(ethtoolRxFlowSpec{}); size != 168 {
+		t.Errorf("unexpected ethtool_rx_flow_spec size %d", size)
+	}
+	if offset := unsafe.Offsetof(ethtoolRxFlowSpec{}.ring_cookie); offset != 152 {
+		t.Errorf("unexpected ethtool_rx_flow_spec ring_cookie offset %d", offset)
+	}
+	if offset := unsafe.Offsetof(ethtoolRxFlowSpec{}.location); offset != 160 {
+		t.Errorf("unexpected ethtool_rx_flow_spec location offset %d", offset)
+	}
+	if size := unsafe.Sizeof(ethtoolRxnfc{}); size != 192 {
+		t.Errorf("unexpected ethtool_rxnfc size %d", size)
+	}
+	if offset := unsafe.Offsetof(ethtoolRxnfc{}.rule_cnt); offset != 184 {
+		t.Errorf("unexpected ethtool_rxnfc rule_cnt offset %d", offset)
+	}
+}
+
+func TestRxNfcRuleFlowSpec(t *testing.T) {
+	rule := RxNfcRule{
+		Spec: RxFlowSpec{
+			FlowType: TCP_V4_FLOW | FLOW_EXT,
+		},
+		RingCookie: 3,
+		Location:   RX_CLS_LOC_ANY,
+	}
+	rule.Spec.Header[0] = 10
+	rule.Spec.Mask[0] = 0xff
+
+	if actual := newRxNfcRule(rule.flowSpec()); actual != rule {
+		t.Errorf("expected %+v, got %+v", rule, actual)
+	}
+}
+
+func TestNTupleRuleCache(t *testing.T) {
+	var e Ethtool
+
+	current := ntupleCacheKey{intf: "eth0", location: 1}
+	expired := ntupleCacheKey{intf: "eth0", location: 2}
+	e.ntupleCache = map[ntupleCacheKey]ntupleCacheEntry{
+		current: {rule: &RxFlowRule{Location: 1}, fetched: time.Now()},
+		expired: {rule: &RxFlowRule{Location: 2}, fetched: time.Now().Add(-ntupleRuleCacheTTL)},
+	}
+
+	if entry, _, ok := e.cachedNTupleRule(current); !ok || entry.rule.Location != 1 {
+		t.Errorf("expected the cached rule, got %+v, %v", entry.rule, ok)
+	}
+	if _, _, ok := e.cachedNTupleRule(expired); ok {
+		t.Error("unexpected expired rule")
+	}
+	if _, ok := e.ntupleCache[expired]; ok {
+		t.Error("expected the expired rule to be evicted")
+	}
+
+	_, gen, _ := e.cachedNTupleRule(current)
+	e.invalidateNTupleCache("eth0")
+	if _, newGen, ok := e.cachedNTupleRule(current); ok || newGen == gen {
+		t.Errorf("expected the rules to be invalidated, got %v, generation %d", ok, newGen)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Self-test flags
+const (
+	ETH_TEST_FL_OFFLINE          = 1 << 0 // online (default) or offline
+	ETH_TEST_FL_FAILED           = 1 << 1 // test passed (default) or failed
+	ETH_TEST_FL_EXTERNAL_LB      = 1 << 2 // external loopback test requested
+	ETH_TEST_FL_EXTERNAL_LB_DONE = 1 << 3 // external loopback test was executed
+)
+
+// following structure comes from uapi/linux/ethtool.h, the test results
+// follow it in memory
+type ethtoolTest struct {
+	cmd      uint32
+	flags    uint32
+	reserved uint32
+	len      uint32
+}
+
+// SelfTestResult contains the result of a NIC self-test. Data holds one
+// value per test, non-zero meaning the test failed, TestInfo maps the test
+// names to these values.
+type SelfTestResult struct {
+	Flags    uint32            `json:"flags"`
+	Len      uint32            `json:"len"`
+	Data     []uint64          `json:"data,omitempty"`
+	TestInfo map[string]uint64 `json:"test_info,omitempty"`
+}
+
+// Failed returns whether at least one of the tests failed.
+func (r *SelfTestResult) Failed() bool {
+	return r.Flags&ETH_TEST_FL_FAILED != 0
+}
+
+// RunSelfTest runs the self-tests of the given interface name. Offline
+// tests, which may interrupt the normal operation of the device, are run
+// as well unless online is true. A failed test doesn't return an error,
+// see the result flags and data.
+func (e *Ethtool) RunSelfTest(intf string, online bool) (*SelfTestResult, error) {
+	names, err := e.getNames(intf, ETH_SS_TEST)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return nil, mapErrno(unix.EOPNOTSUPP)
+	}
+
+	drvinfo, err := e.getDriverInfo(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	// the header is made of two uint64s, keeping the results aligned. The
+	// kernel ignores the requested length and writes as many results as
+	// the driver currently reports, so the buffer is sized after
+	// MAX_GSTRINGS rather than the driver info.
+	hdrLen := int(unsafe.Sizeof(ethtoolTest{}) / 8)
+	buf := make([]uint64, hdrLen+MAX_GSTRINGS)
+
+	test := (*ethtoolTest)(unsafe.Pointer(&buf[0]))
+	test.cmd = ETHTOOL_TEST
+	test.len = drvinfo.testinfo_len
+	if !online {
+		test.flags = ETH_TEST_FL_OFFLINE
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, err
+	}
+
+	if test.len > MAX_GSTRINGS {
+		return nil, fmt.Errorf("ethtool currently doesn't support more than %d entries, received %d", MAX_GSTRINGS, test.len)
+	}
+
+	data := make([]uint64, test.len)
+	copy(data, buf[hdrLen:])
+
+	return &SelfTestResult{
+		Flags:    test.flags,
+		Len:      test.len,
+		Data:     data,
+		TestInfo: testInfo(names, data),
+	}, nil
+}
+
+// testInfo maps the given test names, indexed in the ETH_SS_TEST string
+// set, to their results. Names without result are omitted, as well as
+// results without name.
+func testInfo(names map[string]uint, data []uint64) map[string]uint64 {
+	info := make(map[string]uint64, len(names))
+	for name, index := range names {
+		if index < uint(len(data)) {
+			info[name] = data[index]
+		}
+	}
+	return info
+}
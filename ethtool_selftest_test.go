@@ -0,0 +1,47 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTestInfo(t *testing.T) {
+	names := map[string]uint{"register": 0, "eeprom": 1, "interrupt": 2}
+
+	for _, tc := range []struct {
+		data     []uint64
+		expected map[string]uint64
+	}{
+		{[]uint64{0, 1, 0}, map[string]uint64{"register": 0, "eeprom": 1, "interrupt": 0}},
+		// more results than names, the unnamed ones are only in the data
+		{[]uint64{0, 1, 0, 1, 1}, map[string]uint64{"register": 0, "eeprom": 1, "interrupt": 0}},
+		// fewer results than names
+		{[]uint64{1}, map[string]uint64{"register": 1}},
+		{nil, map[string]uint64{}},
+	} {
+		if info := testInfo(names, tc.data); !reflect.DeepEqual(info, tc.expected) {
+			t.Errorf("expected %v for %v, got %v", tc.expected, tc.data, info)
+		}
+	}
+}
@@ -0,0 +1,563 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatsGroupRegexps are the naming conventions used by StatsByGroup to split
+// a stat name into a group and a suffix. Each expression must have two
+// capturing groups, the first one being the group name and the second one
+// the suffix. Expressions are tried in order, the first one matching wins,
+// so more specific conventions have to come first.
+var StatsGroupRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`^((?:rx|tx)_queue_\d+)_(.+)$`), // rx_queue_1_packets
+	regexp.MustCompile(`^((?:rx|tx)q\d+)_(.+)$`),       // rxq1_pkts
+	regexp.MustCompile(`^([^_]+)_(.+)$`),               // rx_packets
+}
+
+// StatsByGroup retrieves stats of the given interface name grouped by
+// name prefix, see StatsGroupRegexps.
+func (e *Ethtool) StatsByGroup(intf string) (map[string]map[string]uint64, error) {
+	stats, err := e.Stats(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupStats(stats, StatsGroupRegexps), nil
+}
+
+// groupStats splits the stats according to the given conventions. Stats
+// sharing the same first word may match different conventions, in which
+// case the convention used by the majority of them is preferred. Stats not
+// matching any convention end up in the "" group.
+func groupStats(stats map[string]uint64, regexps []*regexp.Regexp) map[string]map[string]uint64 {
+	firstMatch := func(name string) int {
+		for i, re := range regexps {
+			if re.MatchString(name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	// count, for each first word, the stats handled by each convention
+	votes := make(map[string]map[int]int)
+	for name := range stats {
+		i := firstMatch(name)
+		if i == -1 {
+			continue
+		}
+
+		word := firstWord(name)
+		if votes[word] == nil {
+			votes[word] = make(map[int]int)
+		}
+		votes[word][i]++
+	}
+
+	majority := make(map[string]int, len(votes))
+	for word, counts := range votes {
+		best := -1
+		for i, count := range counts {
+			if best == -1 || count > counts[best] || (count == counts[best] && i < best) {
+				best = i
+			}
+		}
+		majority[word] = best
+	}
+
+	result := make(map[string]map[string]uint64)
+	add := func(group, suffix string, value uint64) {
+		if result[group] == nil {
+			result[group] = make(map[string]uint64)
+		}
+		result[group][suffix] = value
+	}
+
+	for name, value := range stats {
+		i := firstMatch(name)
+		if i == -1 {
+			add("", name, value)
+			continue
+		}
+
+		if m := regexps[majority[firstWord(name)]].FindStringSubmatch(name); m != nil {
+			add(m[1], m[2], value)
+			continue
+		}
+
+		m := regexps[i].FindStringSubmatch(name)
+		add(m[1], m[2], value)
+	}
+
+	return result
+}
+
+func firstWord(name string) string {
+	if i := strings.IndexByte(name, '_'); i != -1 {
+		return name[:i]
+	}
+	return name
+}
+
+// FilteredStats retrieves the given stats of the given interface name,
+// stats unknown to the driver being omitted. All the stats are still
+// retrieved from the kernel.
+func (e *Ethtool) FilteredStats(intf string, keys []string) (map[string]uint64, error) {
+	return e.StatsWithFilter(intf, StatsFilter{ExactNames: keys})
+}
+
+// FilteredStatsByPrefix retrieves the stats of the given interface name
+// whose name starts with the given prefix.
+func (e *Ethtool) FilteredStatsByPrefix(intf string, prefix string) (map[string]uint64, error) {
+	return e.StatsWithFilter(intf, StatsFilter{Prefixes: []string{prefix}})
+}
+
+// StatsFilter selects stats by name prefix or exact name, a stat matching
+// any of them being selected.
+type StatsFilter struct {
+	Prefixes   []string `json:"prefixes,omitempty"`
+	ExactNames []string `json:"exact_names,omitempty"`
+}
+
+// StatsWithFilter retrieves the stats of the given interface name selected
+// by the filter. All the stats are still retrieved from the kernel but only
+// the names of the selected ones are allocated, which matters for NICs
+// reporting hundreds of stats.
+func (e *Ethtool) StatsWithFilter(intf string, filter StatsFilter) (map[string]uint64, error) {
+	prefixes := make([][]byte, len(filter.Prefixes))
+	for i, prefix := range filter.Prefixes {
+		prefixes[i] = []byte(prefix)
+	}
+
+	names := make(map[string]struct{}, len(filter.ExactNames))
+	for _, name := range filter.ExactNames {
+		names[name] = struct{}{}
+	}
+
+	return e.filteredStats(intf, func(name []byte) bool {
+		if _, ok := names[string(name)]; ok {
+			return true
+		}
+		for _, prefix := range prefixes {
+			if bytes.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (e *Ethtool) filteredStats(intf string, keep func(name []byte) bool) (map[string]uint64, error) {
+	drvinfo, err := e.getDriverInfo(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.stats(intf, drvinfo, keep)
+}
+
+// StatEntry is a stat along with its index in the stats of the driver.
+type StatEntry struct {
+	Name  string `json:"name"`
+	Value uint64 `json:"value"`
+	Index int    `json:"index"`
+}
+
+// StatsWithIndex retrieves the stats of the given interface name in the
+// order defined by the driver. Stats whose name has already been used are
+// renamed with a _N suffix, N being the number of previous occurrences,
+// while unnamed stats are skipped like in Stats.
+func (e *Ethtool) StatsWithIndex(intf string) ([]StatEntry, error) {
+	drvinfo, err := e.getDriverInfo(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, drvinfo.n_stats)
+	values := make([]uint64, 0, drvinfo.n_stats)
+
+	err = e.rawStats(intf, drvinfo, func(_ int, name []byte, value uint64) {
+		names = append(names, string(name))
+		values = append(values, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return statEntries(names, values), nil
+}
+
+// statEntries returns the entries of the given stats of a driver, indexed
+// by their position in the driver stats.
+func statEntries(names []string, values []uint64) []StatEntry {
+	entries := make([]StatEntry, 0, len(names))
+	seen := make(map[string]int, len(names))
+
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+
+		entries = append(entries, StatEntry{
+			Name:  dedupStatName(seen, name),
+			Value: values[i],
+			Index: i,
+		})
+	}
+
+	return entries
+}
+
+// dedupStatName returns the given name suffixed with the number of its
+// previous occurrences, if any, skipping the suffixed names already used.
+func dedupStatName(seen map[string]int, name string) string {
+	n, ok := seen[name]
+	seen[name] = n + 1
+	if !ok {
+		return name
+	}
+
+	for {
+		suffixed := fmt.Sprintf("%s_%d", name, n)
+		if _, ok := seen[suffixed]; !ok {
+			seen[suffixed] = 1
+			return suffixed
+		}
+		n++
+	}
+}
+
+// ConcurrentStats retrieves the stats of the given interfaces using up to
+// concurrency goroutines. It returns the stats of the interfaces for which
+// the retrieval succeeded, and the errors of the other ones.
+func (e *Ethtool) ConcurrentStats(intfs []string, concurrency int) (map[string]map[string]uint64, map[string]error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > len(intfs) {
+		concurrency = len(intfs)
+	}
+
+	var (
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+		stats  = make(map[string]map[string]uint64, len(intfs))
+		errs   = make(map[string]error)
+		queue  = make(chan string)
+		result = func(intf string, s map[string]uint64, err error) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			if err != nil {
+				errs[intf] = err
+			} else {
+				stats[intf] = s
+			}
+		}
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// each worker uses its own descriptor of the handler socket,
+			// sharing its network namespace
+			worker, err := e.dup()
+			if err != nil {
+				for intf := range queue {
+					result(intf, nil, err)
+				}
+				return
+			}
+			defer worker.Close()
+
+			for intf := range queue {
+				s, err := worker.Stats(intf)
+				result(intf, s, err)
+			}
+		}()
+	}
+
+	for _, intf := range intfs {
+		queue <- intf
+	}
+	close(queue)
+	wg.Wait()
+
+	return stats, errs
+}
+
+// StatsDelta returns, for each stat present in both snapshots, the
+// difference between the newer and the older values. Counters wrapping
+// around between the snapshots are handled by the unsigned arithmetic.
+func StatsDelta(older, newer map[string]uint64) map[string]uint64 {
+	delta := make(map[string]uint64, len(newer))
+	for name, value := range newer {
+		if old, ok := older[name]; ok {
+			delta[name] = value - old
+		}
+	}
+	return delta
+}
+
+// StatsRate returns, for each stat present in both snapshots, the per
+// second rate of change between the snapshots taken d apart.
+func StatsRate(older, newer map[string]uint64, d time.Duration) map[string]float64 {
+	rate := make(map[string]float64, len(newer))
+	if d <= 0 {
+		return rate
+	}
+
+	for name, delta := range StatsDelta(older, newer) {
+		rate[name] = float64(delta) / d.Seconds()
+	}
+	return rate
+}
+
+// IEEEStats contains the IEEE 802.3 MAC counters of an interface.
+type IEEEStats struct {
+	FramesTransmittedOK            uint64 `json:"frames_transmitted_ok"`
+	SingleCollisionFrames          uint64 `json:"single_collision_frames"`
+	MultipleCollisionFrames        uint64 `json:"multiple_collision_frames"`
+	FramesReceivedOK               uint64 `json:"frames_received_ok"`
+	FrameCheckSequenceErrors       uint64 `json:"frame_check_sequence_errors"`
+	AlignmentErrors                uint64 `json:"alignment_errors"`
+	OctetsTransmittedOK            uint64 `json:"octets_transmitted_ok"`
+	FramesWithDeferredXmissions    uint64 `json:"frames_with_deferred_xmissions"`
+	LateCollisions                 uint64 `json:"late_collisions"`
+	FramesAbortedDueToXSColls      uint64 `json:"frames_aborted_due_to_xs_colls"`
+	FramesLostDueToIntMACXmitError uint64 `json:"frames_lost_due_to_int_mac_xmit_error"`
+	CarrierSenseErrors             uint64 `json:"carrier_sense_errors"`
+	OctetsReceivedOK               uint64 `json:"octets_received_ok"`
+	FramesLostDueToIntMACRcvError  uint64 `json:"frames_lost_due_to_int_mac_rcv_error"`
+	MulticastFramesXmittedOK       uint64 `json:"multicast_frames_xmitted_ok"`
+	BroadcastFramesXmittedOK       uint64 `json:"broadcast_frames_xmitted_ok"`
+	FramesWithExcessiveDeferral    uint64 `json:"frames_with_excessive_deferral"`
+	MulticastFramesReceivedOK      uint64 `json:"multicast_frames_received_ok"`
+	BroadcastFramesReceivedOK      uint64 `json:"broadcast_frames_received_ok"`
+	InRangeLengthErrors            uint64 `json:"in_range_length_errors"`
+	OutOfRangeLengthField          uint64 `json:"out_of_range_length_field"`
+	FrameTooLongErrors             uint64 `json:"frame_too_long_errors"`
+}
+
+// ieeeStatsFields maps the ETHTOOL_A_STATS_ETH_MAC_* identifiers, which are
+// the indexes of this slice, to the IEEEStats fields along with the driver
+// stat names looked up when the netlink interface is not available.
+var ieeeStatsFields = []struct {
+	field func(s *IEEEStats) *uint64
+	names []string
+}{
+	{func(s *IEEEStats) *uint64 { return &s.FramesTransmittedOK }, []string{"FramesTransmittedOK", "tx_frames_ok", "tx_packets"}},
+	{func(s *IEEEStats) *uint64 { return &s.SingleCollisionFrames }, []string{"SingleCollisionFrames", "tx_single_collisions", "tx_single_coll_ok"}},
+	{func(s *IEEEStats) *uint64 { return &s.MultipleCollisionFrames }, []string{"MultipleCollisionFrames", "tx_multi_collisions", "tx_multi_coll_ok"}},
+	{func(s *IEEEStats) *uint64 { return &s.FramesReceivedOK }, []string{"FramesReceivedOK", "rx_frames_ok", "rx_packets"}},
+	{func(s *IEEEStats) *uint64 { return &s.FrameCheckSequenceErrors }, []string{"FrameCheckSequenceErrors", "rx_crc_errors", "rx_fcs_errors"}},
+	{func(s *IEEEStats) *uint64 { return &s.AlignmentErrors }, []string{"AlignmentErrors", "rx_align_errors", "rx_frame_errors"}},
+	{func(s *IEEEStats) *uint64 { return &s.OctetsTransmittedOK }, []string{"OctetsTransmittedOK", "tx_octets_ok", "tx_bytes"}},
+	{func(s *IEEEStats) *uint64 { return &s.FramesWithDeferredXmissions }, []string{"FramesWithDeferredXmissions", "tx_deferred", "tx_deferred_ok"}},
+	{func(s *IEEEStats) *uint64 { return &s.LateCollisions }, []string{"LateCollisions", "tx_late_collisions", "tx_late_coll"}},
+	{func(s *IEEEStats) *uint64 { return &s.FramesAbortedDueToXSColls }, []string{"FramesAbortedDueToXSColls", "tx_excess_collisions", "tx_abort_excess_coll"}},
+	{func(s *IEEEStats) *uint64 { return &s.FramesLostDueToIntMACXmitError }, []string{"FramesLostDueToIntMACXmitError", "tx_mac_errors"}},
+	{func(s *IEEEStats) *uint64 { return &s.CarrierSenseErrors }, []string{"CarrierSenseErrors", "tx_carrier_errors", "tx_carrier_sense_errors"}},
+	{func(s *IEEEStats) *uint64 { return &s.OctetsReceivedOK }, []string{"OctetsReceivedOK", "rx_octets_ok", "rx_bytes"}},
+	{func(s *IEEEStats) *uint64 { return &s.FramesLostDueToIntMACRcvError }, []string{"FramesLostDueToIntMACRcvError", "rx_mac_errors"}},
+	{func(s *IEEEStats) *uint64 { return &s.MulticastFramesXmittedOK }, []string{"MulticastFramesXmittedOK", "tx_multicast", "tx_mcast_frames"}},
+	{func(s *IEEEStats) *uint64 { return &s.BroadcastFramesXmittedOK }, []string{"BroadcastFramesXmittedOK", "tx_broadcast", "tx_bcast_frames"}},
+	{func(s *IEEEStats) *uint64 { return &s.FramesWithExcessiveDeferral }, []string{"FramesWithExcessiveDeferral", "tx_excess_deferred"}},
+	{func(s *IEEEStats) *uint64 { return &s.MulticastFramesReceivedOK }, []string{"MulticastFramesReceivedOK", "rx_multicast", "rx_mcast_frames"}},
+	{func(s *IEEEStats) *uint64 { return &s.BroadcastFramesReceivedOK }, []string{"BroadcastFramesReceivedOK", "rx_broadcast", "rx_bcast_frames"}},
+	{func(s *IEEEStats) *uint64 { return &s.InRangeLengthErrors }, []string{"InRangeLengthErrors", "rx_length_errors", "rx_in_range_errors"}},
+	{func(s *IEEEStats) *uint64 { return &s.OutOfRangeLengthField }, []string{"OutOfRangeLengthField", "rx_out_of_range_errors"}},
+	{func(s *IEEEStats) *uint64 { return &s.FrameTooLongErrors }, []string{"FrameTooLongErrors", "rx_long_length_errors", "rx_frame_too_long"}},
+}
+
+// GetIEEEStats retrieves the IEEE 802.3 MAC counters of the given interface
+// name. The ethtool netlink interface is used when the kernel and the driver
+// support it, otherwise the counters are looked up in the driver stats
+// under their usual names.
+func (e *Ethtool) GetIEEEStats(intf string) (*IEEEStats, error) {
+	var result IEEEStats
+
+	if stats, err := netlinkStats(intf, ETHTOOL_STATS_ETH_MAC); err == nil && len(stats) > 0 {
+		for id, value := range stats {
+			if int(id) < len(ieeeStatsFields) {
+				*ieeeStatsFields[id].field(&result) = value
+			}
+		}
+		return &result, nil
+	}
+
+	stats, err := e.Stats(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range ieeeStatsFields {
+		for _, name := range f.names {
+			if value, ok := stats[name]; ok {
+				*f.field(&result) = value
+				break
+			}
+		}
+	}
+
+	return &result, nil
+}
+
+// standard stats groups, see uapi/linux/ethtool_netlink.h
+const (
+	ETHTOOL_STATS_ETH_PHY  = 0
+	ETHTOOL_STATS_ETH_MAC  = 1
+	ETHTOOL_STATS_ETH_CTRL = 2
+	ETHTOOL_STATS_RMON     = 3
+	ETHTOOL_STATS_COUNT    = 4
+)
+
+// standardStatsSets are the string sets of the standard stats, indexed by
+// their ETHTOOL_STATS_* group, along with the stat names indexed by their
+// ETHTOOL_A_STATS_* identifier, see net/ethtool/common.c.
+var standardStatsSets = [ETHTOOL_STATS_COUNT]struct {
+	stringSet int
+	names     []string
+}{
+	ETHTOOL_STATS_ETH_PHY: {ETH_SS_STATS_ETH_PHY, []string{
+		"SymbolErrorDuringCarrier",
+	}},
+	ETHTOOL_STATS_ETH_MAC: {ETH_SS_STATS_ETH_MAC, []string{
+		"FramesTransmittedOK",
+		"SingleCollisionFrames",
+		"MultipleCollisionFrames",
+		"FramesReceivedOK",
+		"FrameCheckSequenceErrors",
+		"AlignmentErrors",
+		"OctetsTransmittedOK",
+		"FramesWithDeferredXmissions",
+		"LateCollisions",
+		"FramesAbortedDueToXSColls",
+		"FramesLostDueToIntMACXmitError",
+		"CarrierSenseErrors",
+		"OctetsReceivedOK",
+		"FramesLostDueToIntMACRcvError",
+		"MulticastFramesXmittedOK",
+		"BroadcastFramesXmittedOK",
+		"FramesWithExcessiveDeferral",
+		"MulticastFramesReceivedOK",
+		"BroadcastFramesReceivedOK",
+		"InRangeLengthErrors",
+		"OutOfRangeLengthField",
+		"FrameTooLongErrors",
+	}},
+	ETHTOOL_STATS_ETH_CTRL: {ETH_SS_STATS_ETH_CTRL, []string{
+		"MACControlFramesTransmitted",
+		"MACControlFramesReceived",
+		"UnsupportedOpcodesReceived",
+	}},
+	ETHTOOL_STATS_RMON: {ETH_SS_STATS_RMON, []string{
+		"etherStatsUndersizePkts",
+		"etherStatsOversizePkts",
+		"etherStatsFragments",
+		"etherStatsJabbers",
+	}},
+}
+
+// namedStandardStats adds to result the stats of the given group, as
+// returned by netlinkStats, under their string set name.
+func namedStandardStats(result map[string]uint64, group uint32, stats map[uint16]uint64) {
+	names := standardStatsSets[group].names
+	for id, value := range stats {
+		if int(id) < len(names) {
+			result[names[id]] = value
+		}
+	}
+}
+
+// standardStats retrieves the standard stats of the given string sets, see
+// the ETH_SS_STATS_* constants, through the ethtool netlink interface. The
+// stats not reported by the driver are omitted.
+func (e *Ethtool) standardStats(intf string, stringSets ...int) (map[string]uint64, error) {
+	result := make(map[string]uint64)
+	for _, stringSet := range stringSets {
+		for group, set := range standardStatsSets {
+			if set.stringSet != stringSet {
+				continue
+			}
+
+			stats, err := netlinkStats(intf, uint32(group))
+			if err != nil {
+				return nil, err
+			}
+			namedStandardStats(result, uint32(group), stats)
+		}
+	}
+	return result, nil
+}
+
+// StandardStats retrieves all the standard stats of the given interface name,
+// as reported by `ethtool -S <intf> --all-groups`. Standard stats require
+// Linux 5.17 or later and driver support.
+func (e *Ethtool) StandardStats(intf string) (map[string]uint64, error) {
+	return e.standardStats(intf, ETH_SS_STATS_ETH_PHY, ETH_SS_STATS_ETH_MAC, ETH_SS_STATS_ETH_CTRL, ETH_SS_STATS_RMON)
+}
+
+// EthPHYStats retrieves the IEEE 802.3 PHY standard stats of the given
+// interface name.
+func (e *Ethtool) EthPHYStats(intf string) (map[string]uint64, error) {
+	return e.standardStats(intf, ETH_SS_STATS_ETH_PHY)
+}
+
+// MACStats retrieves the IEEE 802.3 MAC standard stats of the given
+// interface name, see also GetIEEEStats.
+func (e *Ethtool) MACStats(intf string) (map[string]uint64, error) {
+	return e.standardStats(intf, ETH_SS_STATS_ETH_MAC)
+}
+
+// CtrlStats retrieves the IEEE 802.3 MAC control standard stats of the
+// given interface name.
+func (e *Ethtool) CtrlStats(intf string) (map[string]uint64, error) {
+	return e.standardStats(intf, ETH_SS_STATS_ETH_CTRL)
+}
+
+// RMONStats retrieves the RMON (RFC 2819) standard stats of the given
+// interface name. The packet size histograms are not reported.
+func (e *Ethtool) RMONStats(intf string) (map[string]uint64, error) {
+	return e.standardStats(intf, ETH_SS_STATS_RMON)
+}
+
+// StatsByGroup retrieves stats of the given interface name grouped by
+// name prefix, see StatsGroupRegexps.
+func StatsByGroup(intf string) (map[string]map[string]uint64, error) {
+	e, err := NewEthtool()
+	if err != nil {
+		return nil, err
+	}
+	defer e.Close()
+	return e.StatsByGroup(intf)
+}
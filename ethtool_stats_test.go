@@ -0,0 +1,307 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupStats(t *testing.T) {
+	stats := map[string]uint64{
+		"rx_queue_0_packets": 1,
+		"rx_queue_0_bytes":   2,
+		"rx_queue_1_packets": 3,
+		"rx_packets":         4,
+		"txq0_pkts":          5,
+		"collisions":         6,
+	}
+
+	expected := map[string]map[string]uint64{
+		"rx_queue_0": {"packets": 1, "bytes": 2},
+		"rx_queue_1": {"packets": 3},
+		"rx":         {"packets": 4},
+		"txq0":       {"pkts": 5},
+		"":           {"collisions": 6},
+	}
+
+	actual := groupStats(stats, StatsGroupRegexps)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestStatsDelta(t *testing.T) {
+	older := map[string]uint64{
+		"rx_packets": 100,
+		"tx_packets": math.MaxUint64 - 9,
+		"rx_errors":  5,
+	}
+	newer := map[string]uint64{
+		"rx_packets": 350,
+		"tx_packets": 10,
+		"tx_errors":  1,
+	}
+
+	expected := map[string]uint64{"rx_packets": 250, "tx_packets": 20}
+	if delta := StatsDelta(older, newer); !reflect.DeepEqual(delta, expected) {
+		t.Errorf("expected %v, got %v", expected, delta)
+	}
+
+	expectedRate := map[string]float64{"rx_packets": 125, "tx_packets": 10}
+	if rate := StatsRate(older, newer, 2*time.Second); !reflect.DeepEqual(rate, expectedRate) {
+		t.Errorf("expected %v, got %v", expectedRate, rate)
+	}
+
+	if rate := StatsRate(older, newer, 0); len(rate) != 0 {
+		t.Errorf("expected no rate for a zero duration, got %v", rate)
+	}
+}
+
+func TestFilteredStats(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, intf := range intfs {
+		stats, err := et.Stats(intf.Name)
+		if err != nil || len(stats) == 0 {
+			continue
+		}
+
+		var name string
+		for name = range stats {
+			break
+		}
+
+		filtered, err := et.FilteredStats(intf.Name, []string{name, "nonexistent"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filtered) != 1 {
+			t.Errorf("expected only %s, got %v", name, filtered)
+		}
+		if _, ok := filtered[name]; !ok {
+			t.Errorf("expected %s, got %v", name, filtered)
+		}
+
+		prefix := name[:len(name)/2]
+		byPrefix, err := et.FilteredStatsByPrefix(intf.Name, prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n := range stats {
+			if _, ok := byPrefix[n]; ok != strings.HasPrefix(n, prefix) {
+				t.Errorf("unexpected filtering of %s with prefix %s", n, prefix)
+			}
+		}
+		return
+	}
+
+	t.Skip("no interface reporting stats")
+}
+
+func TestStatsWithFilter(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, intf := range intfs {
+		stats, err := et.Stats(intf.Name)
+		if err != nil || len(stats) < 2 {
+			continue
+		}
+
+		var first, second string
+		for name := range stats {
+			if first == "" {
+				first = name
+			} else if !strings.HasPrefix(name, first[:1]) {
+				second = name
+				break
+			}
+		}
+
+		filter := StatsFilter{Prefixes: []string{first[:1]}, ExactNames: []string{second, "nonexistent"}}
+		filtered, err := et.StatsWithFilter(intf.Name, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name := range stats {
+			if _, ok := filtered[name]; ok != (strings.HasPrefix(name, first[:1]) || name == second) {
+				t.Errorf("unexpected filtering of %s with %+v", name, filter)
+			}
+		}
+
+		if empty, err := et.StatsWithFilter(intf.Name, StatsFilter{}); err != nil || len(empty) != 0 {
+			t.Errorf("expected no stats for an empty filter, got %v, %v", empty, err)
+		}
+		return
+	}
+
+	t.Skip("no interface reporting stats")
+}
+
+func BenchmarkStatsWithFilter(b *testing.B) {
+	et, err := NewEthtool()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer et.Close()
+
+	intfs, err := net.Interfaces()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	filter := StatsFilter{Prefixes: []string{"rx_packets", "tx_packets"}, ExactNames: []string{"rx_errors", "tx_errors"}}
+	for _, intf := range intfs {
+		if stats, err := et.Stats(intf.Name); err != nil || len(stats) == 0 {
+			continue
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := et.StatsWithFilter(intf.Name, filter); err != nil {
+				b.Fatal(err)
+			}
+		}
+		return
+	}
+
+	b.Skip("no interface reporting stats")
+}
+
+func TestDedupStatName(t *testing.T) {
+	seen := make(map[string]int)
+
+	var names []string
+	for _, name := range []string{"rx_packets", "rx_packets_1", "rx_packets", "rx_packets", "tx_packets"} {
+		names = append(names, dedupStatName(seen, name))
+	}
+
+	expected := []string{"rx_packets", "rx_packets_1", "rx_packets_2", "rx_packets_3", "tx_packets"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestStatEntries(t *testing.T) {
+	names := []string{"rx_packets", "", "tx_queue", "tx_queue", "rx_packets", "tx_queue"}
+	values := []uint64{1, 2, 3, 4, 5, 6}
+
+	expected := []StatEntry{
+		{Name: "rx_packets", Value: 1, Index: 0},
+		{Name: "tx_queue", Value: 3, Index: 2},
+		{Name: "tx_queue_1", Value: 4, Index: 3},
+		{Name: "rx_packets_1", Value: 5, Index: 4},
+		{Name: "tx_queue_2", Value: 6, Index: 5},
+	}
+	if entries := statEntries(names, values); !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+
+	if entries := statEntries(nil, nil); len(entries) != 0 {
+		t.Errorf("expected no entries, got %v", entries)
+	}
+}
+
+func TestConcurrentStats(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"nonexistent0"}
+	for _, intf := range intfs {
+		names = append(names, intf.Name)
+	}
+
+	for _, concurrency := range []int{0, 2, 100} {
+		stats, errs := et.ConcurrentStats(names, concurrency)
+		if len(stats)+len(errs) != len(names) {
+			t.Errorf("expected %d results, got %d stats and %d errors", len(names), len(stats), len(errs))
+		}
+		if _, ok := errs["nonexistent0"]; !ok {
+			t.Error("expected an error for nonexistent0")
+		}
+
+		for _, name := range names {
+			_, err := et.Stats(name)
+			if _, ok := stats[name]; ok != (err == nil) {
+				t.Errorf("unexpected result for %s: %v", name, err)
+			}
+		}
+	}
+}
+
+func TestNamedStandardStats(t *testing.T) {
+	result := make(map[string]uint64)
+	namedStandardStats(result, ETHTOOL_STATS_ETH_MAC, map[uint16]uint64{0: 10, 3: 20, 100: 30})
+	namedStandardStats(result, ETHTOOL_STATS_RMON, map[uint16]uint64{3: 1})
+
+	expected := map[string]uint64{
+		"FramesTransmittedOK": 10,
+		"FramesReceivedOK":    20,
+		"etherStatsJabbers":   1,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestStandardStats(t *testing.T) {
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if _, err := e.StandardStats("lo"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -22,9 +22,15 @@
 package ethtool
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"net"
 	"reflect"
+	"strings"
 	"testing"
+
+	"golang.org/x/sys/unix"
 )
 
 func TestGoString(t *testing.T) {
@@ -67,6 +73,24 @@ func TestStats(t *testing.T) {
 	}
 }
 
+func TestStatsNoStats(t *testing.T) {
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// a driver reporting no stats, no further ioctl should be issued
+	stats, err := e.stats("nonexistent0", ethtoolDrvInfo{n_stats: 0}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats == nil || len(stats) != 0 {
+		t.Errorf("expected empty stats, got %v", stats)
+	}
+}
+
 func TestDriverName(t *testing.T) {
 	intfs, err := net.Interfaces()
 	if err != nil {
@@ -107,6 +131,50 @@ func TestBusInfo(t *testing.T) {
 	}
 }
 
+func TestListInterfaces(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	intfs, err := et.ListInterfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drivers := make(map[string]string)
+	for _, intf := range intfs {
+		driver, err := et.DriverName(intf)
+		if err != nil {
+			t.Errorf("unexpected error for listed interface %s: %s", intf, err)
+		}
+		drivers[intf] = driver
+	}
+
+	for intf, driver := range drivers {
+		byDriver, err := et.ListInterfacesByDriver(driver)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found := false
+		for _, name := range byDriver {
+			if drivers[name] != driver {
+				t.Errorf("unexpected interface %s listed for driver %s", name, driver)
+			}
+			found = found || name == intf
+		}
+		if !found {
+			t.Errorf("interface %s not listed for driver %s", intf, driver)
+		}
+	}
+
+	if intfs, err := et.ListInterfacesByDriver("nonexistent"); err != nil || len(intfs) != 0 {
+		t.Errorf("expected no interface, got %v, %v", intfs, err)
+	}
+}
+
 func TestSupportedLinkModes(t *testing.T) {
 	var cases = []struct {
 		inputMask uint64
@@ -165,3 +233,580 @@ func TestFeatures(t *testing.T) {
 		t.Fatalf("loopback interface reported all features available")
 	}
 }
+
+func TestDrvInfoJSON(t *testing.T) {
+	data, err := json.Marshal(DrvInfo{Cmd: ETHTOOL_GDRVINFO, Driver: "virtio_net", NStats: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"driver":"virtio_net","n_priv_flags":0,"n_stats":3,"test_info_len":0,"eedump_len":0,"regdump_len":0}`
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
+
+func TestDrvInfoString(t *testing.T) {
+	info := DrvInfo{Cmd: ETHTOOL_GDRVINFO, Driver: "virtio_net", Version: "1.0.0", BusInfo: "0000:00:03.0", NStats: 4}
+
+	expected := `driver: virtio_net
+version: 1.0.0
+firmware-version: 
+expansion-rom-version: 
+bus-info: 0000:00:03.0
+supports-statistics: yes
+supports-test: no
+supports-eeprom-access: no
+supports-register-dump: no
+supports-priv-flags: no`
+	if s := info.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
+
+func TestChannelsString(t *testing.T) {
+	channels := Channels{MaxCombined: 4, CombinedCount: 2}
+
+	expected := "Pre-set maximums:\nRX:\t\tn/a\nTX:\t\tn/a\nOther:\t\tn/a\nCombined:\t4\n" +
+		"Current hardware settings:\nRX:\t\tn/a\nTX:\t\tn/a\nOther:\t\tn/a\nCombined:\t2"
+	if s := channels.String(); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
+
+func TestCoalesceString(t *testing.T) {
+	s := Coalesce{UseAdaptiveRxCoalesce: 1, RxCoalesceUsecs: 8, TxMaxCoalescedFrames: 64}.String()
+
+	for _, line := range []string{"Adaptive RX: on  TX: off\n", "\nrx-usecs: 8\n", "\ntx-frames: 64\n", "\ntx-frame-high: 0"} {
+		if !strings.Contains(s, line) {
+			t.Errorf("expected %q in %q", line, s)
+		}
+	}
+}
+
+func TestDuplexFromString(t *testing.T) {
+	for _, duplex := range []Duplex{DUPLEX_HALF, DUPLEX_FULL} {
+		parsed, err := DuplexFromString(strings.ToUpper(DuplexName(duplex)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != duplex {
+			t.Errorf("expected %s, got %s", duplex, parsed)
+		}
+	}
+
+	if DUPLEX_UNKNOWN.String() != "unknown" {
+		t.Errorf("unexpected name %s", DUPLEX_UNKNOWN)
+	}
+
+	if _, err := DuplexFromString("unknown"); err == nil {
+		t.Error("expected an error for an unknown duplex mode")
+	}
+}
+
+func TestPortFromString(t *testing.T) {
+	for _, port := range []Port{PORT_TP, PORT_AUI, PORT_BNC, PORT_MII, PORT_FIBRE, PORT_DA, PORT_NONE, PORT_OTHER} {
+		parsed, err := PortFromString(PortName(port))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != port {
+			t.Errorf("expected %s, got %s", port, parsed)
+		}
+	}
+
+	if name := Port(0x10).String(); name != "unknown (16)" {
+		t.Errorf("unexpected name %s", name)
+	}
+
+	if _, err := PortFromString("sfp"); err == nil {
+		t.Error("expected an error for an unknown port type")
+	}
+}
+
+func TestStringSetSorted(t *testing.T) {
+	set := StringSet{"tx-checksumming": 2, "rx-gro": 0, "highdma": 1}
+
+	expected := []string{"highdma", "rx-gro", "tx-checksumming"}
+	if names := set.Sorted(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestFeatureStateString(t *testing.T) {
+	tests := []struct {
+		state    FeatureState
+		expected string
+	}{
+		{FeatureState{Available: true, Requested: true, Active: true}, "on"},
+		{FeatureState{Available: true}, "off"},
+		{FeatureState{Active: true}, "on [fixed]"},
+		{FeatureState{Available: true, NeverChanged: true}, "off [fixed]"},
+		{FeatureState{Available: true, Requested: true}, "off [requested on]"},
+		{FeatureState{Available: true, Active: true}, "on [requested off]"},
+	}
+
+	for _, test := range tests {
+		if s := test.state.String(); s != test.expected {
+			t.Errorf("expected %q for %+v, got %q", test.expected, test.state, s)
+		}
+	}
+}
+
+func TestCoalesceValidate(t *testing.T) {
+	var cases = []struct {
+		coalesce Coalesce
+		valid    bool
+	}{
+		{Coalesce{PktRateLow: 10, PktRateHigh: 5}, true},
+		{Coalesce{UseAdaptiveRxCoalesce: 1, PktRateLow: 10, PktRateHigh: 5}, false},
+		{Coalesce{UseAdaptiveRxCoalesce: 1, PktRateLow: 5, PktRateHigh: 10}, true},
+		{Coalesce{UseAdaptiveRxCoalesce: 1, RxCoalesceUsecsLow: 50, RxCoalesceUsecsHigh: 20}, false},
+		{Coalesce{UseAdaptiveRxCoalesce: 1, RxCoalesceUsecsLow: 50}, true},
+		{Coalesce{UseAdaptiveTxCoalesce: 1, TxCoalesceUsecsLow: 50, TxCoalesceUsecsHigh: 20}, false},
+		{Coalesce{UseAdaptiveRxCoalesce: 1, TxCoalesceUsecsLow: 50, TxCoalesceUsecsHigh: 20}, true},
+	}
+
+	for _, testcase := range cases {
+		err := testcase.coalesce.Validate()
+		if (err == nil) != testcase.valid {
+			t.Errorf("unexpected validation result for %+v: %v", testcase.coalesce, err)
+		}
+	}
+}
+
+func TestDrvHasFeature(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	feats, err := et.FeaturesWithState("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0, len(feats))
+	for name, state := range feats {
+		names = append(names, name)
+
+		active, available, err := et.DrvHasFeature("lo", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if active != state.Active || available != state.Available {
+			t.Errorf("inconsistent feature %q: active %v available %v", name, active, available)
+		}
+	}
+
+	actives, err := et.DrvHasAnyFeature("lo", append(names, "unknown-feature")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if actives[name] != feats[name].Active {
+			t.Errorf("inconsistent feature %q: active %v", name, actives[name])
+		}
+	}
+	if actives["unknown-feature"] {
+		t.Error("unknown feature reported as active")
+	}
+}
+
+func TestGetMTU(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	for _, intf := range intfs {
+		mtu, err := et.GetMTU(intf.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if int(mtu) != intf.MTU {
+			t.Errorf("mtu mismatch for %s: got %d, want %d", intf.Name, mtu, intf.MTU)
+		}
+	}
+}
+
+func TestSetMTUBounds(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	for _, mtu := range []uint32{0, MIN_MTU - 1, MAX_MTU + 1} {
+		if err := et.SetMTU("lo", mtu); err == nil {
+			t.Errorf("expected an error for mtu %d", mtu)
+		}
+	}
+}
+
+func TestLinkModes(t *testing.T) {
+	expected := []LinkMode{
+		{Name: "10baseT_Half", Bit: 0, Speed: 10, Duplex: DUPLEX_HALF, MediaType: "T"},
+		{Name: "1000baseT_Full", Bit: 5, Speed: 1000, Duplex: DUPLEX_FULL, MediaType: "T"},
+		{Name: "10000baseR_FEC", Bit: 20, Speed: 10000, Duplex: DUPLEX_UNKNOWN, MediaType: "R"},
+		{Name: "40000baseKR4_Full", Bit: 23, Speed: 40000, Duplex: DUPLEX_FULL, MediaType: "KR4"},
+		{Name: "25000baseCR_Full", Bit: 31, Speed: 25000, Duplex: DUPLEX_FULL, MediaType: "CR"},
+	}
+
+	actual := LinkModes([]uint32{1<<0 | 1<<5 | 1<<20 | 1<<23 | 1<<31})
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+
+	names := LinkSpeedNames([]uint32{1<<0 | 1<<5 | 1<<20 | 1<<23 | 1<<31})
+	if !reflect.DeepEqual(names, []string{"10baseT_Half", "1000baseT_Full", "10000baseR_FEC", "40000baseKR4_Full", "25000baseCR_Full"}) {
+		t.Errorf("unexpected names %v", names)
+	}
+}
+
+func TestLinkSpeedMbps(t *testing.T) {
+	speeds := LinkSpeedMbps([]uint32{1<<0 | 1<<1 | 1<<5 | 1<<12 | 1<<3})
+	expected := []uint32{SPEED_10, SPEED_100, SPEED_1000, SPEED_10000}
+	if !reflect.DeepEqual(speeds, expected) {
+		t.Errorf("expected %v, got %v", expected, speeds)
+	}
+
+	if speeds := LinkSpeedMbps(nil); len(speeds) != 0 {
+		t.Errorf("expected no speed, got %v", speeds)
+	}
+}
+
+func TestLinkSpeedNamesToMask(t *testing.T) {
+	names := []string{"10baseT_Half", "1000baseT_Full", "25000baseCR_Full"}
+
+	mask, err := LinkSpeedNamesToMask(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(mask) == 0 || mask[0] != 1<<0|1<<5|1<<31 {
+		t.Errorf("unexpected mask %b", mask)
+	}
+
+	if actual := LinkSpeedNames(mask); !reflect.DeepEqual(actual, names) {
+		t.Errorf("expected %v, got %v", names, actual)
+	}
+
+	if _, err := LinkSpeedNamesToMask([]string{"10baseT_Half", "unknown"}); err == nil {
+		t.Error("expected an error for unknown link mode")
+	}
+}
+
+func TestSetCoalesce(t *testing.T) {
+	// tap interfaces support setting rx-frames
+	intf := newTap(t)
+
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	update, err := et.GetCoalesce(intf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update.RxMaxCoalescedFrames++
+
+	applied, err := et.SetCoalesce(intf, update)
+	if errors.Is(err, unix.EOPNOTSUPP) {
+		t.Skip("setting the coalesce config of tap interfaces not supported")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := et.GetCoalesce(intf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != applied || applied.RxMaxCoalescedFrames != update.RxMaxCoalescedFrames {
+		t.Errorf("applied coalesce %+v differs from current %+v", applied, current)
+	}
+}
+
+func TestGetRingParams(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	tested := false
+	for _, intf := range intfs {
+		ring, err := et.GetRing(intf.Name)
+		if err != nil {
+			continue
+		}
+		tested = true
+
+		params, err := et.GetRingParams(intf.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if params.MaxRx != ring.RxMaxPending || params.RxPending != ring.RxPending ||
+			params.MaxTx != ring.TxMaxPending || params.TxPending != ring.TxPending {
+			t.Errorf("ring params %+v inconsistent with ring %+v", params, ring)
+		}
+	}
+
+	if !tested {
+		t.Skip("no interface of this system reports ring parameters")
+	}
+}
+
+func TestRingParamsValidateAgainstMax(t *testing.T) {
+	max := RingParams{MaxRx: 1024, MaxTx: 512}
+
+	if err := (RingParams{RxPending: 1024, TxPending: 256}).ValidateAgainstMax(max); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	err := RingParams{RxPending: 4096, RxJumboPending: 1, TxPending: 512}.ValidateAgainstMax(max)
+	if err == nil || err.Error() != "invalid ring sizes: rx 4096 exceeds maximum 1024, rx-jumbo 1 exceeds maximum 0" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestWOLModeNames(t *testing.T) {
+	actual := WOLModeNames(WOL_MODE_PHY | WOL_MODE_MAGIC)
+	if !reflect.DeepEqual(actual, []string{"phy", "magic"}) {
+		t.Errorf("unexpected WoL mode names %v", actual)
+	}
+}
+
+func TestGetRegDump(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	for _, intf := range intfs {
+		drvinfo, err := e.DriverInfo(intf.Name)
+		if err != nil {
+			continue
+		}
+
+		regs, err := e.GetRegDump(intf.Name)
+		if drvinfo.RegdumpLen == 0 {
+			if err == nil {
+				t.Errorf("expected an error for %s not reporting a register dump", intf.Name)
+			}
+			continue
+		}
+
+		if err == nil && uint32(len(regs)) > drvinfo.RegdumpLen {
+			t.Errorf("register dump of %s larger than announced: %d > %d", intf.Name, len(regs), drvinfo.RegdumpLen)
+		}
+	}
+}
+
+func TestGetEepromBytes(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	for _, intf := range intfs {
+		drvinfo, err := e.DriverInfo(intf.Name)
+		if err != nil {
+			continue
+		}
+
+		eeprom, err := e.GetEepromBytes(intf.Name, 0, 16)
+		if drvinfo.EedumpLen == 0 {
+			if err == nil {
+				t.Errorf("expected an error for %s not reporting an EEPROM", intf.Name)
+			}
+			continue
+		}
+
+		if err == nil && len(eeprom) > 16 {
+			t.Errorf("EEPROM read of %s larger than requested: %d > 16", intf.Name, len(eeprom))
+		}
+	}
+}
+
+func TestEepromBytesBounds(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	// tap interfaces report no EEPROM, the ranges are rejected before
+	// issuing the ioctl
+	intf := newTap(t)
+
+	if _, err := et.GetEepromBytes(intf, 0, math.MaxUint32); err == nil || errors.Is(err, unix.EOPNOTSUPP) {
+		t.Errorf("expected a range error, got %v", err)
+	}
+	if err := et.SetEepromBytes(intf, math.MaxUint32, []byte{0}, 0); err == nil || errors.Is(err, unix.EOPNOTSUPP) {
+		t.Errorf("expected a range error, got %v", err)
+	}
+}
+
+func TestModuleEepromPageBounds(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	ranges := []struct {
+		page           uint8
+		offset, length uint32
+	}{
+		{0, 200, 100},
+		{0, 0, 257},
+		{3, 100, 100},
+		{3, 1, 0xffffffff},
+	}
+
+	for _, r := range ranges {
+		if _, err := et.ModuleEepromPage("lo", r.page, r.offset, r.length); err == nil || errors.Is(err, ErrNotSupported) {
+			t.Errorf("expected a range error for page %d offset %d length %d, got %v", r.page, r.offset, r.length, err)
+		}
+	}
+}
+
+func TestModuleEepromBytesBounds(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	if _, err := et.ModuleEepromBytes("lo", 0, math.MaxUint32); err == nil {
+		t.Error("expected an error for a 4GiB module EEPROM read")
+	}
+
+	// the length is rejected before issuing the ioctl
+	if _, err := et.readModuleEeprom("lo", 0, math.MaxUint32); err == nil || errors.Is(err, unix.EOPNOTSUPP) {
+		t.Errorf("expected a length error, got %v", err)
+	}
+}
+
+func TestGetTimestampingInfo(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	info, err := et.GetTimestampingInfo("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the loopback only provides software timestamps
+	if info.SoTimestamping&SOF_TIMESTAMPING_SOFTWARE == 0 {
+		t.Errorf("expected software timestamps for loopback interface, got %#x", info.SoTimestamping)
+	}
+	if info.HasHardwareTimestamps() {
+		t.Error("unexpected hardware timestamps for loopback interface")
+	}
+}
+
+func TestPermHardwareAddr(t *testing.T) {
+	intfs, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, intf := range intfs {
+		addr, err := PermHardwareAddr(intf.Name)
+		if err != nil {
+			continue
+		}
+
+		str, err := PermAddr(intf.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if addr == nil && str != "" || addr != nil && str != addr.String() {
+			t.Errorf("permanent address mismatch for %s: %q and %v", intf.Name, str, addr)
+		}
+	}
+}
+
+func TestErrorWrapping(t *testing.T) {
+	et, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer et.Close()
+
+	_, err = et.DriverName("nonexistent0")
+	if !errors.Is(err, ErrNoDevice) || !errors.Is(err, unix.ENODEV) {
+		t.Fatalf("expected ErrNoDevice, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), `ETHTOOL_GDRVINFO on "nonexistent0"`) {
+		t.Errorf("expected the operation and the interface name in %q", err)
+	}
+
+	_, err = et.GetMTU("nonexistent0")
+	if !errors.Is(err, ErrNoDevice) || !errors.Is(err, unix.ENODEV) {
+		t.Fatalf("expected ErrNoDevice, got %v", err)
+	}
+}
+
+func BenchmarkStats(b *testing.B) {
+	et, err := NewEthtool()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer et.Close()
+
+	intfs, err := net.Interfaces()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, intf := range intfs {
+		if stats, err := et.Stats(intf.Name); err != nil || len(stats) == 0 {
+			continue
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := et.Stats(intf.Name); err != nil {
+				b.Fatal(err)
+			}
+		}
+		return
+	}
+
+	b.Skip("no interface reporting stats")
+}
@@ -0,0 +1,303 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// Tunable types
+const (
+	ETHTOOL_TUNABLE_UNSPEC = 0
+	ETHTOOL_TUNABLE_U8     = 1
+	ETHTOOL_TUNABLE_U16    = 2
+	ETHTOOL_TUNABLE_U32    = 3
+	ETHTOOL_TUNABLE_U64    = 4
+	ETHTOOL_TUNABLE_STRING = 5
+	ETHTOOL_TUNABLE_S8     = 6
+	ETHTOOL_TUNABLE_S16    = 7
+	ETHTOOL_TUNABLE_S32    = 8
+	ETHTOOL_TUNABLE_S64    = 9
+)
+
+// Driver tunables
+const (
+	ETHTOOL_RX_COPYBREAK          = 1
+	ETHTOOL_TX_COPYBREAK          = 2
+	ETHTOOL_PFC_PREVENTION_TOUT   = 3
+	ETHTOOL_TX_COPYBREAK_BUF_SIZE = 4
+)
+
+// PHY tunables
+const (
+	ETHTOOL_PHY_DOWNSHIFT      = 1
+	ETHTOOL_PHY_FAST_LINK_DOWN = 2
+	ETHTOOL_PHY_EDPD           = 3
+)
+
+// PHY tunables special values
+const (
+	DOWNSHIFT_DEV_DEFAULT_COUNT = 0xff
+	DOWNSHIFT_DEV_DISABLE       = 0
+
+	ETHTOOL_PHY_FAST_LINK_DOWN_ON  = 0
+	ETHTOOL_PHY_FAST_LINK_DOWN_OFF = 0xff
+
+	ETHTOOL_PHY_EDPD_DFLT_TX_MSECS = 0xffff
+	ETHTOOL_PHY_EDPD_NO_TX         = 0xfffe
+	ETHTOOL_PHY_EDPD_DISABLE       = 0
+)
+
+// type of the known driver tunables, the kernel rejects requests not using
+// the expected one
+var tunableTypes = map[uint32]uint32{
+	ETHTOOL_RX_COPYBREAK:          ETHTOOL_TUNABLE_U32,
+	ETHTOOL_TX_COPYBREAK:          ETHTOOL_TUNABLE_U32,
+	ETHTOOL_PFC_PREVENTION_TOUT:   ETHTOOL_TUNABLE_U16,
+	ETHTOOL_TX_COPYBREAK_BUF_SIZE: ETHTOOL_TUNABLE_U32,
+}
+
+// type of the known PHY tunables, the kernel rejects requests not using
+// the expected one
+var phyTunableTypes = map[uint32]uint32{
+	ETHTOOL_PHY_DOWNSHIFT:      ETHTOOL_TUNABLE_U8,
+	ETHTOOL_PHY_FAST_LINK_DOWN: ETHTOOL_TUNABLE_U8,
+	ETHTOOL_PHY_EDPD:           ETHTOOL_TUNABLE_U16,
+}
+
+// following structure comes from uapi/linux/ethtool.h, data being large
+// enough for the integer tunables
+type ethtoolTunable struct {
+	cmd     uint32
+	id      uint32
+	type_id uint32
+	len     uint32
+	data    [8]byte
+}
+
+// PhyTunable is the value of a PHY tunable.
+type PhyTunable struct {
+	ID    uint32 `json:"id"`
+	Value int    `json:"value"`
+}
+
+func tunableLen(typeID uint32) (uint32, error) {
+	switch typeID {
+	case ETHTOOL_TUNABLE_U8, ETHTOOL_TUNABLE_S8:
+		return 1, nil
+	case ETHTOOL_TUNABLE_U16, ETHTOOL_TUNABLE_S16:
+		return 2, nil
+	case ETHTOOL_TUNABLE_U32, ETHTOOL_TUNABLE_S32:
+		return 4, nil
+	case ETHTOOL_TUNABLE_U64, ETHTOOL_TUNABLE_S64:
+		return 8, nil
+	}
+	return 0, fmt.Errorf("unsupported tunable type %d", typeID)
+}
+
+func (t *ethtoolTunable) value() int {
+	p := unsafe.Pointer(&t.data[0])
+	switch t.type_id {
+	case ETHTOOL_TUNABLE_U8:
+		return int(*(*uint8)(p))
+	case ETHTOOL_TUNABLE_U16:
+		return int(*(*uint16)(p))
+	case ETHTOOL_TUNABLE_U32:
+		return int(*(*uint32)(p))
+	case ETHTOOL_TUNABLE_U64:
+		return int(*(*uint64)(p))
+	case ETHTOOL_TUNABLE_S8:
+		return int(*(*int8)(p))
+	case ETHTOOL_TUNABLE_S16:
+		return int(*(*int16)(p))
+	case ETHTOOL_TUNABLE_S32:
+		return int(*(*int32)(p))
+	case ETHTOOL_TUNABLE_S64:
+		return int(*(*int64)(p))
+	}
+	return 0
+}
+
+func (t *ethtoolTunable) setValue(v int) {
+	p := unsafe.Pointer(&t.data[0])
+	switch t.type_id {
+	case ETHTOOL_TUNABLE_U8:
+		*(*uint8)(p) = uint8(v)
+	case ETHTOOL_TUNABLE_U16:
+		*(*uint16)(p) = uint16(v)
+	case ETHTOOL_TUNABLE_U32:
+		*(*uint32)(p) = uint32(v)
+	case ETHTOOL_TUNABLE_U64:
+		*(*uint64)(p) = uint64(v)
+	case ETHTOOL_TUNABLE_S8:
+		*(*int8)(p) = int8(v)
+	case ETHTOOL_TUNABLE_S16:
+		*(*int16)(p) = int16(v)
+	case ETHTOOL_TUNABLE_S32:
+		*(*int32)(p) = int32(v)
+	case ETHTOOL_TUNABLE_S64:
+		*(*int64)(p) = int64(v)
+	}
+}
+
+func newEthtoolTunable(cmd, id, typeID uint32) (ethtoolTunable, error) {
+	l, err := tunableLen(typeID)
+	if err != nil {
+		return ethtoolTunable{}, err
+	}
+
+	return ethtoolTunable{
+		cmd:     cmd,
+		id:      id,
+		type_id: typeID,
+		len:     l,
+	}, nil
+}
+
+func phyTunableType(id uint32) (uint32, error) {
+	typeID, ok := phyTunableTypes[id]
+	if !ok {
+		return 0, fmt.Errorf("unknown PHY tunable %d", id)
+	}
+	return typeID, nil
+}
+
+func tunableType(id uint32) (uint32, error) {
+	typeID, ok := tunableTypes[id]
+	if !ok {
+		return 0, fmt.Errorf("unknown tunable %d", id)
+	}
+	return typeID, nil
+}
+
+// GetTunable retrieves the value of the given ETHTOOL_* driver tunable, e.g.
+// ETHTOOL_RX_COPYBREAK, of the given interface name. Most virtual
+// interfaces don't have tunables and return ErrNotSupported.
+func (e *Ethtool) GetTunable(intf string, id uint32) (int, error) {
+	typeID, err := tunableType(id)
+	if err != nil {
+		return 0, err
+	}
+
+	tunable, err := newEthtoolTunable(ETHTOOL_GTUNABLE, id, typeID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&tunable)); err != nil {
+		return 0, err
+	}
+
+	return tunable.value(), nil
+}
+
+// SetTunable sets the value of the given ETHTOOL_* driver tunable of the
+// given interface name.
+func (e *Ethtool) SetTunable(intf string, id uint32, val int) error {
+	typeID, err := tunableType(id)
+	if err != nil {
+		return err
+	}
+
+	tunable, err := newEthtoolTunable(ETHTOOL_STUNABLE, id, typeID)
+	if err != nil {
+		return err
+	}
+	tunable.setValue(val)
+
+	return e.ioctl(intf, unsafe.Pointer(&tunable))
+}
+
+// GetTunableNames returns the names of the driver tunables known by the
+// kernel, indexed by ID. The string set is the same for all the drivers, a
+// tunable listed there may still not be supported by the given interface.
+func (e *Ethtool) GetTunableNames(intf string) (StringSet, error) {
+	return e.getNames(intf, ETH_SS_TUNABLES)
+}
+
+// GetPhyTunable retrieves the value of the given ETHTOOL_PHY_* tunable of
+// the given interface name.
+func (e *Ethtool) GetPhyTunable(intf string, id uint32) (int, error) {
+	typeID, err := phyTunableType(id)
+	if err != nil {
+		return 0, err
+	}
+
+	tunable, err := newEthtoolTunable(ETHTOOL_PHY_GTUNABLE, id, typeID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := e.ioctl(intf, unsafe.Pointer(&tunable)); err != nil {
+		return 0, err
+	}
+
+	return tunable.value(), nil
+}
+
+// SetPhyTunable sets the value of the given ETHTOOL_PHY_* tunable of the
+// given interface name.
+func (e *Ethtool) SetPhyTunable(intf string, id uint32, val int) error {
+	typeID, err := phyTunableType(id)
+	if err != nil {
+		return err
+	}
+
+	tunable, err := newEthtoolTunable(ETHTOOL_PHY_STUNABLE, id, typeID)
+	if err != nil {
+		return err
+	}
+	tunable.setValue(val)
+
+	return e.ioctl(intf, unsafe.Pointer(&tunable))
+}
+
+// GetPhyTunables retrieves the values of the known PHY tunables supported
+// by the given interface name, sorted by ID.
+func (e *Ethtool) GetPhyTunables(intf string) ([]PhyTunable, error) {
+	var tunables []PhyTunable
+	for _, id := range []uint32{ETHTOOL_PHY_DOWNSHIFT, ETHTOOL_PHY_FAST_LINK_DOWN, ETHTOOL_PHY_EDPD} {
+		val, err := e.GetPhyTunable(intf, id)
+		if err != nil {
+			// tunables not supported by the PHY are skipped
+			if errors.Is(err, ErrNotSupported) {
+				continue
+			}
+			return nil, err
+		}
+		tunables = append(tunables, PhyTunable{ID: id, Value: val})
+	}
+
+	return tunables, nil
+}
+
+// SetPhyTunables sets the given PHY tunables of the given interface name.
+func (e *Ethtool) SetPhyTunables(intf string, tunables []PhyTunable) error {
+	for _, tunable := range tunables {
+		if err := e.SetPhyTunable(intf, tunable.ID, tunable.Value); err != nil {
+			return fmt.Errorf("failed to set PHY tunable %d: %w", tunable.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"testing"
+)
+
+func TestTunableValue(t *testing.T) {
+	var cases = []struct {
+		typeID uint32
+		value  int
+	}{
+		{ETHTOOL_TUNABLE_U8, DOWNSHIFT_DEV_DEFAULT_COUNT},
+		{ETHTOOL_TUNABLE_U16, ETHTOOL_PHY_EDPD_NO_TX},
+		{ETHTOOL_TUNABLE_U32, 1 << 20},
+		{ETHTOOL_TUNABLE_S8, -3},
+		{ETHTOOL_TUNABLE_S32, -1 << 20},
+	}
+
+	for _, testcase := range cases {
+		tunable, err := newEthtoolTunable(ETHTOOL_PHY_STUNABLE, 0, testcase.typeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tunable.setValue(testcase.value)
+		if value := tunable.value(); value != testcase.value {
+			t.Errorf("expected %d for type %d, got %d", testcase.value, testcase.typeID, value)
+		}
+	}
+
+	if _, err := newEthtoolTunable(ETHTOOL_PHY_STUNABLE, 0, ETHTOOL_TUNABLE_STRING); err == nil {
+		t.Error("expected an error for string tunables")
+	}
+}
+
+func TestGetTunableNames(t *testing.T) {
+	e, err := NewEthtool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	names, err := e.GetTunableNames("lo")
+	if err != nil {
+		t.Skipf("unable to get the tunable names: %s", err)
+	}
+
+	if id, ok := names["rx-copybreak"]; !ok || id != ETHTOOL_RX_COPYBREAK {
+		t.Errorf("unexpected tunable names %v", names)
+	}
+
+	if _, err := e.GetTunable("lo", 0xff); err == nil {
+		t.Error("expected an error for an unknown tunable")
+	}
+}
@@ -0,0 +1,295 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// DefaultWatcherPollInterval is the default interval at which a Watcher
+// polls the speed and features of the watched interfaces.
+const DefaultWatcherPollInterval = 5 * time.Second
+
+// WatchEventType is the type of a link change reported by a Watcher.
+type WatchEventType int
+
+// Watch event types
+const (
+	LinkUp WatchEventType = iota
+	LinkDown
+	SpeedChange
+	FeatureChange
+)
+
+var watchEventTypeNames = map[WatchEventType]string{
+	LinkUp:        "LinkUp",
+	LinkDown:      "LinkDown",
+	SpeedChange:   "SpeedChange",
+	FeatureChange: "FeatureChange",
+}
+
+func (t WatchEventType) String() string {
+	return watchEventTypeNames[t]
+}
+
+// LinkInfo is the state of a link as seen by a Watcher.
+type LinkInfo struct {
+	Up       bool            `json:"up"`
+	Speed    uint32          `json:"speed"`
+	Duplex   Duplex          `json:"duplex"`
+	Features map[string]bool `json:"features,omitempty"`
+}
+
+// WatchEvent is a link change reported by a Watcher.
+type WatchEvent struct {
+	Interface string         `json:"interface"`
+	Type      WatchEventType `json:"type"`
+	Previous  LinkInfo       `json:"previous"`
+	Current   LinkInfo       `json:"current"`
+}
+
+// Watcher monitors link state changes. Link up/down changes are reported as
+// soon as the kernel notifies them through netlink, speed and feature
+// changes, which don't always generate netlink notifications, are polled
+// every PollInterval.
+type Watcher struct {
+	PollInterval time.Duration
+
+	e         *Ethtool
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewWatcher returns a new link state watcher
+func NewWatcher() (*Watcher, error) {
+	e, err := NewEthtool()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		PollInterval: DefaultWatcherPollInterval,
+		e:            e,
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// Watch starts monitoring the given interface names. The returned channel is
+// closed when the watcher is closed.
+func (w *Watcher) Watch(intfs []string) (<-chan WatchEvent, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: unix.RTMGRP_LINK}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	states := make(map[string]LinkInfo, len(intfs))
+	for _, intf := range intfs {
+		states[intf] = w.linkInfo(intf)
+	}
+
+	events := make(chan WatchEvent, 16)
+	notify := make(chan string, 16)
+
+	w.wg.Add(2)
+	go func() {
+		defer w.wg.Done()
+		defer unix.Close(fd)
+		defer close(notify)
+		w.readNetlink(fd, notify)
+	}()
+
+	go func() {
+		defer w.wg.Done()
+		defer close(events)
+
+		interval := w.PollInterval
+		if interval <= 0 {
+			interval = DefaultWatcherPollInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			var updated []string
+
+			select {
+			case <-w.done:
+				return
+			case intf, ok := <-notify:
+				if !ok {
+					return
+				}
+				if _, watched := states[intf]; watched {
+					updated = append(updated, intf)
+				}
+			case <-ticker.C:
+				updated = append(updated, intfs...)
+			}
+
+			for _, intf := range updated {
+				current := w.linkInfo(intf)
+				for _, event := range diffLinkInfo(intf, states[intf], current) {
+					select {
+					case events <- event:
+					case <-w.done:
+						return
+					}
+				}
+				states[intf] = current
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// readNetlink sends the name of the interfaces reported by the netlink link
+// notifications until the watcher is closed.
+func (w *Watcher) readNetlink(fd int, notify chan<- string) {
+	buf := make([]byte, unix.Getpagesize())
+	for {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, 500)
+		if err == unix.EINTR || n == 0 {
+			continue
+		} else if err != nil {
+			return
+		}
+
+		n, _, err = unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+
+		for i := range msgs {
+			if msgs[i].Header.Type != unix.RTM_NEWLINK && msgs[i].Header.Type != unix.RTM_DELLINK {
+				continue
+			}
+
+			attrs, err := syscall.ParseNetlinkRouteAttr(&msgs[i])
+			if err != nil {
+				continue
+			}
+
+			for _, attr := range attrs {
+				if attr.Attr.Type == unix.IFLA_IFNAME {
+					select {
+					case notify <- goString(attr.Value):
+					case <-w.done:
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func (w *Watcher) linkInfo(intf string) LinkInfo {
+	var info LinkInfo
+
+	// fallback to the administrative state for drivers not reporting links
+	if state, err := w.e.LinkState(intf); err == nil {
+		info.Up = state != 0
+	} else if ifi, err := net.InterfaceByName(intf); err == nil {
+		info.Up = ifi.Flags&net.FlagUp != 0
+	}
+
+	var ecmd EthtoolCmd
+	if speed, err := w.e.CmdGet(&ecmd, intf); err == nil {
+		info.Speed = speed
+		info.Duplex = Duplex(ecmd.Duplex)
+	}
+
+	if features, err := w.e.Features(intf); err == nil {
+		info.Features = features
+	}
+
+	return info
+}
+
+func diffLinkInfo(intf string, previous, current LinkInfo) []WatchEvent {
+	var events []WatchEvent
+	add := func(t WatchEventType) {
+		events = append(events, WatchEvent{
+			Interface: intf,
+			Type:      t,
+			Previous:  previous,
+			Current:   current,
+		})
+	}
+
+	if previous.Up != current.Up {
+		if current.Up {
+			add(LinkUp)
+		} else {
+			add(LinkDown)
+		}
+	}
+
+	if previous.Speed != current.Speed || previous.Duplex != current.Duplex {
+		add(SpeedChange)
+	}
+
+	if len(previous.Features) != len(current.Features) {
+		add(FeatureChange)
+	} else {
+		for name, active := range current.Features {
+			if previous.Features[name] != active {
+				add(FeatureChange)
+				break
+			}
+		}
+	}
+
+	return events
+}
+
+// Close stops the watcher and closes the channels returned by Watch.
+func (w *Watcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+		w.e.Close()
+	})
+}
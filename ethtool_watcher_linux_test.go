@@ -0,0 +1,73 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLinkInfo(t *testing.T) {
+	var cases = []struct {
+		previous LinkInfo
+		current  LinkInfo
+		expected []WatchEventType
+	}{
+		{LinkInfo{Up: true, Speed: 1000}, LinkInfo{Up: true, Speed: 1000}, nil},
+		{LinkInfo{Up: false}, LinkInfo{Up: true}, []WatchEventType{LinkUp}},
+		{LinkInfo{Up: true, Speed: 1000}, LinkInfo{Up: false, Speed: 0}, []WatchEventType{LinkDown, SpeedChange}},
+		{LinkInfo{Up: true, Duplex: DUPLEX_HALF}, LinkInfo{Up: true, Duplex: DUPLEX_FULL}, []WatchEventType{SpeedChange}},
+		{
+			LinkInfo{Features: map[string]bool{"rx-gro": true}},
+			LinkInfo{Features: map[string]bool{"rx-gro": false}},
+			[]WatchEventType{FeatureChange},
+		},
+	}
+
+	for _, testcase := range cases {
+		var actual []WatchEventType
+		for _, event := range diffLinkInfo("eth0", testcase.previous, testcase.current) {
+			actual = append(actual, event.Type)
+		}
+
+		if !reflect.DeepEqual(actual, testcase.expected) {
+			t.Errorf("expected %v, got %v", testcase.expected, actual)
+		}
+	}
+}
+
+func TestWatcherClose(t *testing.T) {
+	w, err := NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := w.Watch([]string{"lo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Close()
+
+	for range events {
+	}
+}
@@ -0,0 +1,233 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+// Package ethtoolmetrics exposes ethtool statistics in the Prometheus text
+// exposition format.
+package ethtoolmetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/safchain/ethtool"
+)
+
+// MetricPrefix is the prefix of all the metric names.
+const MetricPrefix = "ethtool_"
+
+// MetricName returns the metric name of the given stat name. The characters
+// not allowed in metric names, like dots and dashes, are converted to
+// underscores, and the names starting with a digit are prefixed by "stat_".
+func MetricName(stat string) string {
+	name := []byte(stat)
+	for i, c := range name {
+		if !(c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			name[i] = '_'
+		}
+	}
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		return MetricPrefix + "stat_" + string(name)
+	}
+	return MetricPrefix + string(name)
+}
+
+// labelName is like MetricName for label names, which can't contain colons.
+func labelName(name string) string {
+	l := []byte(name)
+	for i, c := range l {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' && i > 0) {
+			l[i] = '_'
+		}
+	}
+	return string(l)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels returns the label set made of the interface label, followed
+// by the given labels and the constant labels sorted by name.
+func formatLabels(intf string, labels [][2]string, constLabels map[string]string) string {
+	var b strings.Builder
+
+	write := func(name, value string) {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(labelName(name))
+		b.WriteString(`="`)
+		b.WriteString(labelValueReplacer.Replace(value))
+		b.WriteByte('"')
+	}
+
+	write("interface", intf)
+	for _, label := range labels {
+		write(label[0], label[1])
+	}
+
+	names := make([]string, 0, len(constLabels))
+	for name := range constLabels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		write(name, constLabels[name])
+	}
+
+	return "{" + b.String() + "}"
+}
+
+// checkConstLabels returns an error when one of the given constant labels
+// has the name of a label set by the package.
+func checkConstLabels(constLabels map[string]string, reserved ...string) error {
+	for name := range constLabels {
+		for _, r := range reserved {
+			if labelName(name) == r {
+				return fmt.Errorf("constant label %q clashes with the %s label", name, r)
+			}
+		}
+	}
+	return nil
+}
+
+// WritePrometheusStats writes the given stats of an interface, as returned
+// by Stats, as Prometheus counters sorted by name. The constLabels are added
+// to every sample. Distinct stats mapping to the same metric name, see
+// MetricName, are reported as an error before anything is written. Use
+// WriteInterfacesStats to expose the stats of several interfaces.
+func WritePrometheusStats(w io.Writer, intf string, stats map[string]uint64, constLabels map[string]string) error {
+	return WriteInterfacesStats(w, map[string]map[string]uint64{intf: stats}, constLabels)
+}
+
+// WriteInterfacesStats writes the given stats of several interfaces, by
+// interface name, like WritePrometheusStats. The samples of a metric are
+// grouped, its type being declared once, as expected in an exposition.
+func WriteInterfacesStats(w io.Writer, stats map[string]map[string]uint64, constLabels map[string]string) error {
+	if err := checkConstLabels(constLabels, "interface"); err != nil {
+		return err
+	}
+
+	// values of each metric by interface name
+	samples := make(map[string]map[string]uint64)
+	for intf, intfStats := range stats {
+		metrics := make(map[string]string, len(intfStats))
+		for stat, value := range intfStats {
+			name := MetricName(stat)
+			if other, ok := metrics[name]; ok {
+				return fmt.Errorf("stats %q and %q of %s have the same metric name %s", other, stat, intf, name)
+			}
+			metrics[name] = stat
+
+			if samples[name] == nil {
+				samples[name] = make(map[string]uint64)
+			}
+			samples[name][intf] = value
+		}
+	}
+
+	names := make([]string, 0, len(samples))
+	for name := range samples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	intfs := make([]string, 0, len(stats))
+	for intf := range stats {
+		intfs = append(intfs, intf)
+	}
+	sort.Strings(intfs)
+	labels := make(map[string]string, len(intfs))
+	for _, intf := range intfs {
+		labels[intf] = formatLabels(intf, nil, constLabels)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, name := range names {
+		fmt.Fprintf(bw, "# TYPE %s counter\n", name)
+		for _, intf := range intfs {
+			if value, ok := samples[name][intf]; ok {
+				fmt.Fprintf(bw, "%s%s %d\n", name, labels[intf], value)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteDriverInfo writes the driver information of an interface as
+// Prometheus gauges: an ethtool_driver_info gauge always set to 1 and
+// labelled by the driver, versions and bus, and one gauge per size
+// reported by the driver. Use WriteInterfacesDriverInfo to expose the
+// driver information of several interfaces.
+func WriteDriverInfo(w io.Writer, intf string, info ethtool.DrvInfo, constLabels map[string]string) error {
+	return WriteInterfacesDriverInfo(w, map[string]ethtool.DrvInfo{intf: info}, constLabels)
+}
+
+// WriteInterfacesDriverInfo writes the driver information of several
+// interfaces, by interface name, like WriteDriverInfo, the type of each
+// gauge being declared once.
+func WriteInterfacesDriverInfo(w io.Writer, infos map[string]ethtool.DrvInfo, constLabels map[string]string) error {
+	err := checkConstLabels(constLabels, "interface", "driver", "version", "firmware_version", "expansion_rom_version", "bus_info")
+	if err != nil {
+		return err
+	}
+
+	intfs := make([]string, 0, len(infos))
+	for intf := range infos {
+		intfs = append(intfs, intf)
+	}
+	sort.Strings(intfs)
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# TYPE %sdriver_info gauge\n", MetricPrefix)
+	for _, intf := range intfs {
+		info := infos[intf]
+		infoLabels := formatLabels(intf, [][2]string{
+			{"driver", info.Driver},
+			{"version", info.Version},
+			{"firmware_version", info.FwVersion},
+			{"expansion_rom_version", info.EromVersion},
+			{"bus_info", info.BusInfo},
+		}, constLabels)
+		fmt.Fprintf(bw, "%sdriver_info%s 1\n", MetricPrefix, infoLabels)
+	}
+
+	for _, gauge := range []struct {
+		name  string
+		value func(ethtool.DrvInfo) uint32
+	}{
+		{"driver_n_priv_flags", func(info ethtool.DrvInfo) uint32 { return info.NPrivFlags }},
+		{"driver_n_stats", func(info ethtool.DrvInfo) uint32 { return info.NStats }},
+		{"driver_test_info_len", func(info ethtool.DrvInfo) uint32 { return info.TestInfoLen }},
+		{"driver_eedump_len", func(info ethtool.DrvInfo) uint32 { return info.EedumpLen }},
+		{"driver_regdump_len", func(info ethtool.DrvInfo) uint32 { return info.RegdumpLen }},
+	} {
+		fmt.Fprintf(bw, "# TYPE %s%s gauge\n", MetricPrefix, gauge.name)
+		for _, intf := range intfs {
+			labels := formatLabels(intf, nil, constLabels)
+			fmt.Fprintf(bw, "%s%s%s %d\n", MetricPrefix, gauge.name, labels, gauge.value(infos[intf]))
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,146 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtoolmetrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/safchain/ethtool"
+)
+
+func TestMetricName(t *testing.T) {
+	for stat, expected := range map[string]string{
+		"rx_bytes":          "ethtool_rx_bytes",
+		"rx-queue.0.bytes":  "ethtool_rx_queue_0_bytes",
+		"tx_pkts_[0]":       "ethtool_tx_pkts__0_",
+		"64_byte_frames_rx": "ethtool_stat_64_byte_frames_rx",
+	} {
+		if name := MetricName(stat); name != expected {
+			t.Errorf("expected %s for %q, got %s", expected, stat, name)
+		}
+	}
+}
+
+func TestWritePrometheusStats(t *testing.T) {
+	var b bytes.Buffer
+	stats := map[string]uint64{"tx-packets": 2, "rx.packets": 1}
+	if err := WritePrometheusStats(&b, "eth0", stats, map[string]string{"zone": "a", "host": `h"1`}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# TYPE ethtool_rx_packets counter
+ethtool_rx_packets{interface="eth0",host="h\"1",zone="a"} 1
+# TYPE ethtool_tx_packets counter
+ethtool_tx_packets{interface="eth0",host="h\"1",zone="a"} 2
+`
+	if b.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestWriteInterfacesStats(t *testing.T) {
+	var b bytes.Buffer
+	stats := map[string]map[string]uint64{
+		"eth1": {"rx_packets": 3},
+		"eth0": {"rx_packets": 1, "tx_packets": 2},
+	}
+	if err := WriteInterfacesStats(&b, stats, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# TYPE ethtool_rx_packets counter
+ethtool_rx_packets{interface="eth0"} 1
+ethtool_rx_packets{interface="eth1"} 3
+# TYPE ethtool_tx_packets counter
+ethtool_tx_packets{interface="eth0"} 2
+`
+	if b.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestConstLabelsClash(t *testing.T) {
+	var b bytes.Buffer
+	if err := WritePrometheusStats(&b, "eth0", map[string]uint64{"rx_packets": 1}, map[string]string{"interface": "eth1"}); err == nil {
+		t.Error("expected an error for the interface constant label")
+	}
+	if err := WriteDriverInfo(&b, "eth0", ethtool.DrvInfo{}, map[string]string{"driver": "e1000"}); err == nil {
+		t.Error("expected an error for the driver constant label")
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", b.String())
+	}
+}
+
+func TestWritePrometheusStatsConflict(t *testing.T) {
+	var b bytes.Buffer
+	stats := map[string]uint64{"rx-packets": 1, "rx.packets": 1}
+	if err := WritePrometheusStats(&b, "eth0", stats, nil); err == nil {
+		t.Error("expected an error for conflicting metric names")
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected nothing written, got %q", b.String())
+	}
+}
+
+func TestWriteDriverInfo(t *testing.T) {
+	var b bytes.Buffer
+	info := ethtool.DrvInfo{Driver: "virtio_net", Version: "1.0.0", BusInfo: "0000:00:03.0", NStats: 4}
+	if err := WriteDriverInfo(&b, "eth0", info, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"# TYPE ethtool_driver_info gauge\n",
+		`ethtool_driver_info{interface="eth0",driver="virtio_net",version="1.0.0",firmware_version="",expansion_rom_version="",bus_info="0000:00:03.0"} 1` + "\n",
+		"ethtool_driver_n_stats{interface=\"eth0\"} 4\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("expected %q in:\n%s", line, b.String())
+		}
+	}
+}
+
+func TestWriteInterfacesDriverInfo(t *testing.T) {
+	var b bytes.Buffer
+	infos := map[string]ethtool.DrvInfo{
+		"eth0": {Driver: "virtio_net", NStats: 4},
+		"eth1": {Driver: "e1000", NStats: 8},
+	}
+	if err := WriteInterfacesDriverInfo(&b, infos, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(b.String(), "# TYPE ethtool_driver_n_stats gauge\n"); n != 1 {
+		t.Errorf("expected the ethtool_driver_n_stats type once, got %d in:\n%s", n, b.String())
+	}
+	for _, line := range []string{
+		"# TYPE ethtool_driver_n_stats gauge\nethtool_driver_n_stats{interface=\"eth0\"} 4\nethtool_driver_n_stats{interface=\"eth1\"} 8\n",
+		`ethtool_driver_info{interface="eth1",driver="e1000",version="",firmware_version="",expansion_rom_version="",bus_info=""} 1` + "\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("expected %q in:\n%s", line, b.String())
+		}
+	}
+}
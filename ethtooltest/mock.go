@@ -0,0 +1,244 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+// Package ethtooltest provides a fake ethtool handler for the tests of the
+// consumers of the ethtool package.
+package ethtooltest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/safchain/ethtool"
+	"github.com/safchain/ethtool/flowhash"
+)
+
+// EthtoolMock is an ethtool.EthtoolHandle returning the predetermined
+// responses of its maps, indexed by interface name. Operations on an
+// interface missing from the map of the operation fail with ethtool.ErrNoDevice.
+// Setters update the maps.
+type EthtoolMock struct {
+	mu sync.Mutex
+
+	StatsByIntf         map[string]map[string]uint64
+	FeaturesByIntf      map[string]map[string]bool
+	DriverInfoByIntf    map[string]ethtool.DrvInfo
+	ChannelsByIntf      map[string]ethtool.Channels
+	CoalesceByIntf      map[string]ethtool.Coalesce
+	LinkStateByIntf     map[string]uint32
+	PermAddrByIntf      map[string]string
+	ModuleEepromByIntf  map[string][]byte
+	IndirectTableByIntf map[string]flowhash.IndirectTable
+
+	Closed bool
+}
+
+var _ ethtool.EthtoolHandle = (*EthtoolMock)(nil)
+
+func notFound(op, intf string) error {
+	return fmt.Errorf("ethtool %s on %q: %w", op, intf, ethtool.ErrNoDevice)
+}
+
+// Stats returns the stats of the given interface name.
+func (m *EthtoolMock) Stats(intf string) (map[string]uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.StatsByIntf[intf]
+	if !ok {
+		return nil, notFound("Stats", intf)
+	}
+
+	result := make(map[string]uint64, len(stats))
+	for name, value := range stats {
+		result[name] = value
+	}
+	return result, nil
+}
+
+// Features returns the features of the given interface name.
+func (m *EthtoolMock) Features(intf string) (map[string]bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	features, ok := m.FeaturesByIntf[intf]
+	if !ok {
+		return nil, notFound("Features", intf)
+	}
+
+	result := make(map[string]bool, len(features))
+	for name, value := range features {
+		result[name] = value
+	}
+	return result, nil
+}
+
+// Change updates the features of the given interface name, failing if one
+// of them is unknown.
+func (m *EthtoolMock) Change(intf string, config map[string]bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	features, ok := m.FeaturesByIntf[intf]
+	if !ok {
+		return notFound("Change", intf)
+	}
+
+	for name := range config {
+		if _, ok := features[name]; !ok {
+			return fmt.Errorf("unsupported feature %q", name)
+		}
+	}
+	for name, value := range config {
+		features[name] = value
+	}
+	return nil
+}
+
+// DriverInfo returns the driver information of the given interface name.
+func (m *EthtoolMock) DriverInfo(intf string) (ethtool.DrvInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, ok := m.DriverInfoByIntf[intf]
+	if !ok {
+		return ethtool.DrvInfo{}, notFound("DriverInfo", intf)
+	}
+	return info, nil
+}
+
+// GetChannels returns the channels of the given interface name.
+func (m *EthtoolMock) GetChannels(intf string) (ethtool.Channels, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	channels, ok := m.ChannelsByIntf[intf]
+	if !ok {
+		return ethtool.Channels{}, notFound("GetChannels", intf)
+	}
+	return channels, nil
+}
+
+// SetChannels sets the channels of the given interface name.
+func (m *EthtoolMock) SetChannels(intf string, channels ethtool.Channels) (ethtool.Channels, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.ChannelsByIntf[intf]; !ok {
+		return ethtool.Channels{}, notFound("SetChannels", intf)
+	}
+	m.ChannelsByIntf[intf] = channels
+	return channels, nil
+}
+
+// GetCoalesce returns the coalescing parameters of the given interface
+// name.
+func (m *EthtoolMock) GetCoalesce(intf string) (ethtool.Coalesce, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	coalesce, ok := m.CoalesceByIntf[intf]
+	if !ok {
+		return ethtool.Coalesce{}, notFound("GetCoalesce", intf)
+	}
+	return coalesce, nil
+}
+
+// LinkState returns the link state of the given interface name.
+func (m *EthtoolMock) LinkState(intf string) (uint32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.LinkStateByIntf[intf]
+	if !ok {
+		return 0, notFound("LinkState", intf)
+	}
+	return state, nil
+}
+
+// PermAddr returns the permanent address of the given interface name.
+func (m *EthtoolMock) PermAddr(intf string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	addr, ok := m.PermAddrByIntf[intf]
+	if !ok {
+		return "", notFound("PermAddr", intf)
+	}
+	return addr, nil
+}
+
+// ModuleEeprom returns the module EEPROM of the given interface name.
+func (m *EthtoolMock) ModuleEeprom(intf string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	eeprom, ok := m.ModuleEepromByIntf[intf]
+	if !ok {
+		return nil, notFound("ModuleEeprom", intf)
+	}
+	return append([]byte(nil), eeprom...), nil
+}
+
+// ModuleEepromHex returns the module EEPROM of the given interface name,
+// hex encoded.
+func (m *EthtoolMock) ModuleEepromHex(intf string) (string, error) {
+	eeprom, err := m.ModuleEeprom(intf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(eeprom), nil
+}
+
+// GetIndirectTable returns the RSS indirection table of the given interface
+// name.
+func (m *EthtoolMock) GetIndirectTable(intf string) (flowhash.IndirectTable, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	table, ok := m.IndirectTableByIntf[intf]
+	if !ok {
+		return nil, notFound("GetIndirectTable", intf)
+	}
+	return append(flowhash.IndirectTable(nil), table...), nil
+}
+
+// SetIndirectTable sets the RSS indirection table of the given interface
+// name.
+func (m *EthtoolMock) SetIndirectTable(intf string, table flowhash.IndirectTable) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.IndirectTableByIntf[intf]; !ok {
+		return notFound("SetIndirectTable", intf)
+	}
+	m.IndirectTableByIntf[intf] = append(flowhash.IndirectTable(nil), table...)
+	return nil
+}
+
+// Close marks the handler as closed.
+func (m *EthtoolMock) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Closed = true
+}
@@ -0,0 +1,90 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtooltest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/safchain/ethtool"
+	"github.com/safchain/ethtool/flowhash"
+)
+
+// linkUp is a consumer of the ethtool package
+func linkUp(h ethtool.EthtoolHandle, intf string) (bool, error) {
+	defer h.Close()
+
+	state, err := h.LinkState(intf)
+	return state == 1, err
+}
+
+func TestEthtoolMock(t *testing.T) {
+	m := &EthtoolMock{
+		LinkStateByIntf: map[string]uint32{"eth0": 1},
+		FeaturesByIntf: map[string]map[string]bool{
+			"eth0": {"rx-gro": true, "tx-checksum-ipv4": false},
+		},
+		ModuleEepromByIntf: map[string][]byte{"eth0": {0x03, 0x04}},
+	}
+
+	up, err := linkUp(m, "eth0")
+	if err != nil || !up {
+		t.Errorf("expected eth0 to be up, got %v, %v", up, err)
+	}
+	if !m.Closed {
+		t.Error("expected the handler to be closed")
+	}
+
+	if _, err := m.LinkState("eth1"); !errors.Is(err, ethtool.ErrNoDevice) {
+		t.Errorf("expected ErrNoDevice, got %v", err)
+	}
+
+	if err := m.Change("eth0", map[string]bool{"tx-checksum-ipv4": true}); err != nil {
+		t.Fatal(err)
+	}
+	features, err := m.Features("eth0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]bool{"rx-gro": true, "tx-checksum-ipv4": true}; !reflect.DeepEqual(features, expected) {
+		t.Errorf("expected %v, got %v", expected, features)
+	}
+	if err := m.Change("eth0", map[string]bool{"unknown": true}); err == nil {
+		t.Error("expected an error for an unknown feature")
+	}
+
+	if eeprom, err := m.ModuleEepromHex("eth0"); err != nil || eeprom != "0304" {
+		t.Errorf("expected 0304, got %s, %v", eeprom, err)
+	}
+
+	if err := m.SetIndirectTable("eth0", flowhash.IndirectTable{0, 1}); !errors.Is(err, ethtool.ErrNoDevice) {
+		t.Errorf("expected ErrNoDevice, got %v", err)
+	}
+	m.IndirectTableByIntf = map[string]flowhash.IndirectTable{"eth0": {0, 0}}
+	if err := m.SetIndirectTable("eth0", flowhash.IndirectTable{0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if table, err := m.GetIndirectTable("eth0"); err != nil || !reflect.DeepEqual(table, flowhash.IndirectTable{0, 1}) {
+		t.Errorf("expected [0 1], got %v, %v", table, err)
+	}
+}
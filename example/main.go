@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/safchain/ethtool"
 )
@@ -28,6 +29,18 @@ func main() {
 	}
 	fmt.Printf("features: %+v\n", features)
 
+	featureStates, err := e.FeaturesWithState(*name)
+	if err != nil {
+		panic(err.Error())
+	}
+	names, err := e.FeatureNamesSorted(*name)
+	if err != nil {
+		panic(err.Error())
+	}
+	for _, name := range names {
+		fmt.Printf("feature %s: %s\n", name, featureStates[name])
+	}
+
 	stats, err := e.Stats(*name)
 	if err != nil {
 		panic(err.Error())
@@ -57,7 +70,7 @@ func main() {
 	if err != nil {
 		panic(err.Error())
 	}
-	fmt.Printf("msg lvl get: %+v\n", msgLvlGet)
+	fmt.Printf("msg lvl get: %+v (%s)\n", msgLvlGet, strings.Join(ethtool.MsgLevelNames(msgLvlGet), " "))
 
 	drvInfo, err := e.DriverInfo(*name)
 	if err != nil {
@@ -0,0 +1,274 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+// Package flowhash provides helpers to inspect RSS flow hash indirection
+// tables.
+package flowhash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IndirectTable is an RSS indirection table, each entry being the index of
+// the RX queue receiving the flows hashed to it.
+type IndirectTable []uint32
+
+// number of entries per line of the text format of an indirection table
+const entriesPerLine = 8
+
+// MarshalText returns the table in the format of `ethtool -x`, each line
+// starting with the index of its first entry followed by up to 8 entries.
+func (t IndirectTable) MarshalText() ([]byte, error) {
+	var b bytes.Buffer
+	for i, queue := range t {
+		if i%entriesPerLine == 0 {
+			fmt.Fprintf(&b, "%5d: ", i)
+		}
+		fmt.Fprintf(&b, " %5d", queue)
+		if i%entriesPerLine == entriesPerLine-1 || i == len(t)-1 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalText parses a table in the format of MarshalText. The index
+// starting each line has to match the number of entries parsed so far.
+func (t *IndirectTable) UnmarshalText(text []byte) error {
+	table := IndirectTable{}
+	for n, line := range strings.Split(string(text), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		index, err := strconv.ParseUint(strings.TrimSuffix(fields[0], ":"), 10, 32)
+		if err != nil || !strings.HasSuffix(fields[0], ":") {
+			return fmt.Errorf("line %d: invalid index %q", n+1, fields[0])
+		}
+		if index != uint64(len(table)) {
+			return fmt.Errorf("line %d: index %d, expected %d", n+1, index, len(table))
+		}
+
+		for _, field := range fields[1:] {
+			queue, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid queue index %q", n+1, field)
+			}
+			table = append(table, uint32(queue))
+		}
+	}
+
+	*t = table
+	return nil
+}
+
+// RSSContext is the configuration of an RSS context, context 0 being the
+// default one.
+type RSSContext struct {
+	ID       uint32        `json:"id"`
+	Table    IndirectTable `json:"table"`
+	Key      []byte        `json:"key,omitempty"`
+	HashFunc uint8         `json:"hash_func"` // bitmask of the enabled hash functions
+}
+
+// Equal returns true if both contexts have the same configuration.
+func (c *RSSContext) Equal(other *RSSContext) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+
+	if c.ID != other.ID || c.HashFunc != other.HashFunc || len(c.Table) != len(other.Table) || !bytes.Equal(c.Key, other.Key) {
+		return false
+	}
+	for i := range c.Table {
+		if c.Table[i] != other.Table[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a deep copy of the context.
+func (c *RSSContext) Clone() *RSSContext {
+	if c == nil {
+		return nil
+	}
+
+	clone := *c
+	if c.Table != nil {
+		clone.Table = append(IndirectTable{}, c.Table...)
+	}
+	if c.Key != nil {
+		clone.Key = append([]byte{}, c.Key...)
+	}
+	return &clone
+}
+
+// Validate checks that the table isn't empty and that every entry is the
+// index of one of the ringCount RX queues, as the kernel would otherwise
+// reject the table with EINVAL.
+func (t IndirectTable) Validate(ringCount uint32) error {
+	if len(t) == 0 {
+		return errors.New("empty indirection table")
+	}
+
+	for i, queue := range t {
+		if queue >= ringCount {
+			return fmt.Errorf("entry %d: queue index %d exceeds ring count %d", i, queue, ringCount)
+		}
+	}
+
+	return nil
+}
+
+// Custom assigns explicit queues to the entries of an indirection table,
+// e.g. to pin flows to NUMA-local queues.
+type Custom struct {
+	Entries []uint32 `json:"entries"`
+}
+
+// FromTable returns the Custom assignment reproducing the given table, as a
+// base to modify an existing table.
+func FromTable(t IndirectTable) *Custom {
+	return &Custom{Entries: append([]uint32(nil), t...)}
+}
+
+// Fill sets the entries of the table to the Custom entries, repeated when
+// the table is larger and truncated when it is smaller. The table is left
+// untouched if there are no entries.
+func (c *Custom) Fill(t IndirectTable) {
+	if len(c.Entries) == 0 {
+		return
+	}
+
+	for i := range t {
+		t[i] = c.Entries[i%len(c.Entries)]
+	}
+}
+
+// Histogram returns the number of entries of the table pointing to each
+// queue.
+func (t IndirectTable) Histogram() map[uint32]int {
+	counts := make(map[uint32]int)
+	for _, queue := range t {
+		counts[queue]++
+	}
+	return counts
+}
+
+// IsBalanced returns true if the ringCount queues receive the same share of
+// entries of the table, within tolerance percent, see
+// OccupancyReport.IsBalanced.
+func (t IndirectTable) IsBalanced(ringCount uint32, tolerance float64) bool {
+	return t.OccupancyReport().IsBalanced(ringCount, tolerance)
+}
+
+// MaxQueue returns the highest queue index of the table, 0 if empty.
+func (t IndirectTable) MaxQueue() uint32 {
+	var max uint32
+	for _, queue := range t {
+		if queue > max {
+			max = queue
+		}
+	}
+	return max
+}
+
+// MinQueue returns the lowest queue index of the table, 0 if empty.
+func (t IndirectTable) MinQueue() uint32 {
+	if len(t) == 0 {
+		return 0
+	}
+
+	min := t[0]
+	for _, queue := range t[1:] {
+		if queue < min {
+			min = queue
+		}
+	}
+	return min
+}
+
+// QueueOccupancy is the number of entries of an indirection table pointing
+// to a queue.
+type QueueOccupancy struct {
+	Queue      uint32  `json:"queue"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// OccupancyReport is the occupancy of the queues referenced by an
+// indirection table, sorted by queue.
+type OccupancyReport []QueueOccupancy
+
+// OccupancyReport returns the occupancy of each queue referenced by the table.
+func (t IndirectTable) OccupancyReport() OccupancyReport {
+	counts := t.Histogram()
+
+	report := make(OccupancyReport, 0, len(counts))
+	for queue, count := range counts {
+		report = append(report, QueueOccupancy{
+			Queue:      queue,
+			Count:      count,
+			Percentage: float64(count) / float64(len(t)) * 100,
+		})
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Queue < report[j].Queue
+	})
+
+	return report
+}
+
+// IsBalanced returns true if the percentage of every one of the ringCount
+// queues is within tolerance percent of an even distribution across them,
+// queues not referenced by the report being counted as receiving nothing.
+// A report referencing a queue beyond ringCount is never balanced.
+func (r OccupancyReport) IsBalanced(ringCount uint32, tolerance float64) bool {
+	if len(r) == 0 {
+		return true
+	}
+
+	percentages := make(map[uint32]float64, len(r))
+	for _, occupancy := range r {
+		if occupancy.Queue >= ringCount {
+			return false
+		}
+		percentages[occupancy.Queue] = occupancy.Percentage
+	}
+
+	ideal := 100 / float64(ringCount)
+	for queue := uint32(0); queue < ringCount; queue++ {
+		if math.Abs(percentages[queue]-ideal) > tolerance {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,266 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package flowhash
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOccupancyReport(t *testing.T) {
+	table := IndirectTable{2, 0, 1, 0, 2, 0, 1, 0}
+
+	expected := OccupancyReport{
+		{Queue: 0, Count: 4, Percentage: 50},
+		{Queue: 1, Count: 2, Percentage: 25},
+		{Queue: 2, Count: 2, Percentage: 25},
+	}
+
+	report := table.OccupancyReport()
+	if !reflect.DeepEqual(report, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, report)
+	}
+
+	if report.IsBalanced(3, 10) {
+		t.Error("expected report to be unbalanced with a 10% tolerance")
+	}
+
+	if !report.IsBalanced(3, 20) {
+		t.Error("expected report to be balanced with a 20% tolerance")
+	}
+
+	// spread evenly over 2 of 4 rings
+	report = IndirectTable{0, 1, 0, 1}.OccupancyReport()
+	if !report.IsBalanced(2, 0) {
+		t.Error("expected report to be balanced over 2 rings")
+	}
+	if report.IsBalanced(4, 20) {
+		t.Error("expected report to be unbalanced with unused rings")
+	}
+	if report.IsBalanced(1, 100) {
+		t.Error("expected report to be unbalanced with queues beyond the ring count")
+	}
+}
+
+func TestIndirectTableValidate(t *testing.T) {
+	if err := (IndirectTable{0, 1, 2, 3, 0, 1, 2, 3}).Validate(4); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if err := (IndirectTable{}).Validate(4); err == nil {
+		t.Error("expected an error for an empty table")
+	}
+
+	err := (IndirectTable{0, 1, 2, 3, 0, 1, 2, 8}).Validate(4)
+	if err == nil || err.Error() != "entry 7: queue index 8 exceeds ring count 4" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCustomFill(t *testing.T) {
+	for _, tt := range []struct {
+		entries  []uint32
+		expected IndirectTable
+	}{
+		{[]uint32{3, 1}, IndirectTable{3, 1, 3, 1, 3, 1}},
+		{[]uint32{0, 1, 2, 3, 4, 5, 6, 7}, IndirectTable{0, 1, 2, 3, 4, 5}},
+		{nil, IndirectTable{9, 9, 9, 9, 9, 9}},
+	} {
+		table := IndirectTable{9, 9, 9, 9, 9, 9}
+		(&Custom{Entries: tt.entries}).Fill(table)
+		if !reflect.DeepEqual(table, tt.expected) {
+			t.Errorf("expected %v for %v, got %v", tt.expected, tt.entries, table)
+		}
+	}
+}
+
+func TestCustomFillProperties(t *testing.T) {
+	const ringCount = 8
+
+	for _, size := range []int{1, 7, 64, 128} {
+		// no entries, n == 0, the table is left untouched
+		table := make(IndirectTable, size)
+		for i := range table {
+			table[i] = uint32(i)
+		}
+		(&Custom{}).Fill(table)
+		for i, queue := range table {
+			if queue != uint32(i) {
+				t.Fatalf("size %d, no queues: entry %d changed to queue %d", size, i, queue)
+			}
+		}
+
+		for start := uint32(0); start < ringCount; start++ {
+			for n := uint32(1); start+n <= ringCount; n++ {
+				custom := &Custom{}
+				for q := start; q < start+n; q++ {
+					custom.Entries = append(custom.Entries, q)
+				}
+
+				table := make(IndirectTable, size)
+				custom.Fill(table)
+
+				if err := table.Validate(ringCount); err != nil {
+					t.Fatalf("size %d, queues %d-%d: %s", size, start, start+n-1, err)
+				}
+				for i, queue := range table {
+					if queue < start || queue >= start+n {
+						t.Fatalf("size %d, queues %d-%d: entry %d points to queue %d", size, start, start+n-1, i, queue)
+					}
+				}
+
+				// cyclic filling spreads the entries evenly, within one
+				counts := table.Histogram()
+				for q := start; q < start+n && int(q-start) < size; q++ {
+					if count := counts[q]; count < size/int(n) || count > size/int(n)+1 {
+						t.Fatalf("size %d, queues %d-%d: queue %d has %d entries", size, start, start+n-1, q, count)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFromTable(t *testing.T) {
+	table := IndirectTable{0, 1, 0, 1}
+	custom := FromTable(table)
+	custom.Entries[0] = 2
+
+	if table[0] != 0 {
+		t.Error("FromTable shouldn't alias the table")
+	}
+
+	filled := make(IndirectTable, 4)
+	custom.Fill(filled)
+	if !reflect.DeepEqual(filled, IndirectTable{2, 1, 0, 1}) {
+		t.Errorf("unexpected table %v", filled)
+	}
+}
+
+func TestIndirectTableText(t *testing.T) {
+	table := IndirectTable{0, 1, 2, 3, 0, 1, 2, 3, 0, 1}
+
+	text, err := table.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "    0:      0     1     2     3     0     1     2     3\n" +
+		"    8:      0     1\n"
+	if string(text) != expected {
+		t.Errorf("expected %q, got %q", expected, text)
+	}
+
+	var parsed IndirectTable
+	if err := parsed.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, table) {
+		t.Errorf("expected %v, got %v", table, parsed)
+	}
+
+	for _, invalid := range []string{
+		"    0:      0     1\n    8:      0\n",
+		"    0:      0     x\n",
+		"    0       0     1\n",
+	} {
+		if err := parsed.UnmarshalText([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestIndirectTableJSON(t *testing.T) {
+	table := IndirectTable{3, 2, 1, 0}
+
+	b, err := json.Marshal(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed IndirectTable
+	if err := json.Unmarshal(b, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, table) {
+		t.Errorf("expected %v, got %v", table, parsed)
+	}
+}
+
+func TestRSSContextEqualClone(t *testing.T) {
+	ctx := &RSSContext{ID: 1, Table: IndirectTable{0, 1, 0, 1}, Key: []byte{0x6d, 0x5a}, HashFunc: 1}
+
+	clone := ctx.Clone()
+	if !ctx.Equal(clone) {
+		t.Fatalf("expected %+v to equal its clone %+v", ctx, clone)
+	}
+
+	clone.Table[0] = 1
+	clone.Key[0] = 0
+	if ctx.Table[0] != 0 || ctx.Key[0] != 0x6d {
+		t.Fatal("clone shouldn't alias the context")
+	}
+	if ctx.Equal(clone) {
+		t.Error("expected contexts to differ")
+	}
+
+	other := ctx.Clone()
+	other.HashFunc = 2
+	if ctx.Equal(other) {
+		t.Error("expected contexts with different hash functions to differ")
+	}
+
+	if !(*RSSContext)(nil).Equal(nil) || ctx.Equal(nil) {
+		t.Error("unexpected nil comparison")
+	}
+}
+
+func TestIndirectTableHistogram(t *testing.T) {
+	table := IndirectTable{2, 5, 2, 3, 2, 5, 3, 5}
+
+	expected := map[uint32]int{2: 3, 3: 2, 5: 3}
+	if histogram := table.Histogram(); !reflect.DeepEqual(histogram, expected) {
+		t.Errorf("expected %v, got %v", expected, histogram)
+	}
+
+	if table.MinQueue() != 2 || table.MaxQueue() != 5 {
+		t.Errorf("unexpected queue range %d-%d", table.MinQueue(), table.MaxQueue())
+	}
+
+	if table.IsBalanced(6, 10) {
+		t.Error("expected the table to be unbalanced with the unused rings 0, 1 and 4")
+	}
+
+	balanced := IndirectTable{0, 1, 2, 0, 1, 2, 0, 1}
+	if balanced.IsBalanced(3, 8) {
+		t.Error("expected the table to be unbalanced with a 8% tolerance")
+	}
+	if !balanced.IsBalanced(3, 9) {
+		t.Error("expected the table to be balanced with a 9% tolerance")
+	}
+
+	var empty IndirectTable
+	if empty.MinQueue() != 0 || empty.MaxQueue() != 0 || len(empty.Histogram()) != 0 || !empty.IsBalanced(4, 0) {
+		t.Error("unexpected results for an empty table")
+	}
+}
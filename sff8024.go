@@ -0,0 +1,258 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Module identifiers, see SFF-8024 table 4-1
+const (
+	SFF8024_ID_UNKNOWN        = 0x00
+	SFF8024_ID_GBIC           = 0x01
+	SFF8024_ID_SOLDERED       = 0x02
+	SFF8024_ID_SFP            = 0x03
+	SFF8024_ID_300_PIN        = 0x04
+	SFF8024_ID_XENPAK         = 0x05
+	SFF8024_ID_XFP            = 0x06
+	SFF8024_ID_XFF            = 0x07
+	SFF8024_ID_XFP_E          = 0x08
+	SFF8024_ID_XPAK           = 0x09
+	SFF8024_ID_X2             = 0x0a
+	SFF8024_ID_DWDM_SFP       = 0x0b
+	SFF8024_ID_QSFP           = 0x0c
+	SFF8024_ID_QSFP_PLUS      = 0x0d
+	SFF8024_ID_CXP            = 0x0e
+	SFF8024_ID_HD4X           = 0x0f
+	SFF8024_ID_HD8X           = 0x10
+	SFF8024_ID_QSFP28         = 0x11
+	SFF8024_ID_CXP2           = 0x12
+	SFF8024_ID_CDFP           = 0x13
+	SFF8024_ID_HD4X_FANOUT    = 0x14
+	SFF8024_ID_HD8X_FANOUT    = 0x15
+	SFF8024_ID_CDFP_S3        = 0x16
+	SFF8024_ID_MICRO_QSFP     = 0x17
+	SFF8024_ID_QSFP_DD        = 0x18
+	SFF8024_ID_OSFP           = 0x19
+	SFF8024_ID_SFP_DD         = 0x1a
+	SFF8024_ID_DSFP           = 0x1b
+	SFF8024_ID_QSFP_PLUS_CMIS = 0x1e
+)
+
+var sff8024IdentifierNames = map[uint8]string{
+	SFF8024_ID_UNKNOWN:        "no module present, unknown, or unspecified",
+	SFF8024_ID_GBIC:           "GBIC",
+	SFF8024_ID_SOLDERED:       "module soldered to motherboard",
+	SFF8024_ID_SFP:            "SFP",
+	SFF8024_ID_300_PIN:        "300 pin XBI",
+	SFF8024_ID_XENPAK:         "XENPAK",
+	SFF8024_ID_XFP:            "XFP",
+	SFF8024_ID_XFF:            "XFF",
+	SFF8024_ID_XFP_E:          "XFP-E",
+	SFF8024_ID_XPAK:           "XPAK",
+	SFF8024_ID_X2:             "X2",
+	SFF8024_ID_DWDM_SFP:       "DWDM-SFP",
+	SFF8024_ID_QSFP:           "QSFP",
+	SFF8024_ID_QSFP_PLUS:      "QSFP+",
+	SFF8024_ID_CXP:            "CXP",
+	SFF8024_ID_HD4X:           "Shielded Mini Multilane HD 4X",
+	SFF8024_ID_HD8X:           "Shielded Mini Multilane HD 8X",
+	SFF8024_ID_QSFP28:         "QSFP28",
+	SFF8024_ID_CXP2:           "CXP2/CXP28",
+	SFF8024_ID_CDFP:           "CDFP Style 1/Style 2",
+	SFF8024_ID_HD4X_FANOUT:    "Shielded Mini Multilane HD 4X Fanout Cable",
+	SFF8024_ID_HD8X_FANOUT:    "Shielded Mini Multilane HD 8X Fanout Cable",
+	SFF8024_ID_CDFP_S3:        "CDFP Style 3",
+	SFF8024_ID_MICRO_QSFP:     "microQSFP",
+	SFF8024_ID_QSFP_DD:        "QSFP-DD Double Density 8X Pluggable Transceiver",
+	SFF8024_ID_OSFP:           "OSFP 8X Pluggable Transceiver",
+	SFF8024_ID_SFP_DD:         "SFP-DD Double Density 2X Pluggable Transceiver",
+	SFF8024_ID_DSFP:           "DSFP Dual Small Form Factor Pluggable Transceiver",
+	SFF8024_ID_QSFP_PLUS_CMIS: "QSFP+ or later with CMIS",
+}
+
+// Connector types, see SFF-8024 table 4-3
+const (
+	SFF8024_CTOR_UNKNOWN         = 0x00
+	SFF8024_CTOR_SC              = 0x01
+	SFF8024_CTOR_FC_STYLE_1      = 0x02
+	SFF8024_CTOR_FC_STYLE_2      = 0x03
+	SFF8024_CTOR_BNC_TNC         = 0x04
+	SFF8024_CTOR_FC_COAX         = 0x05
+	SFF8024_CTOR_FIBER_JACK      = 0x06
+	SFF8024_CTOR_LC              = 0x07
+	SFF8024_CTOR_MT_RJ           = 0x08
+	SFF8024_CTOR_MU              = 0x09
+	SFF8024_CTOR_SG              = 0x0a
+	SFF8024_CTOR_OPT_PT          = 0x0b
+	SFF8024_CTOR_MPO             = 0x0c
+	SFF8024_CTOR_MPO_2           = 0x0d
+	SFF8024_CTOR_HSDC_II         = 0x20
+	SFF8024_CTOR_COPPER_PT       = 0x21
+	SFF8024_CTOR_RJ45            = 0x22
+	SFF8024_CTOR_NO_SEPARABLE    = 0x23
+	SFF8024_CTOR_MXC_2x16        = 0x24
+	SFF8024_CTOR_CS_OPTICAL      = 0x25
+	SFF8024_CTOR_CS_OPTICAL_MINI = 0x26
+	SFF8024_CTOR_MPO_2X12        = 0x27
+	SFF8024_CTOR_MPO_1X16        = 0x28
+)
+
+var sff8024ConnectorNames = map[uint8]string{
+	SFF8024_CTOR_UNKNOWN:         "unknown or unspecified",
+	SFF8024_CTOR_SC:              "SC",
+	SFF8024_CTOR_FC_STYLE_1:      "Fibre Channel Style 1 copper",
+	SFF8024_CTOR_FC_STYLE_2:      "Fibre Channel Style 2 copper",
+	SFF8024_CTOR_BNC_TNC:         "BNC/TNC",
+	SFF8024_CTOR_FC_COAX:         "Fibre Channel coaxial headers",
+	SFF8024_CTOR_FIBER_JACK:      "FibreJack",
+	SFF8024_CTOR_LC:              "LC",
+	SFF8024_CTOR_MT_RJ:           "MT-RJ",
+	SFF8024_CTOR_MU:              "MU",
+	SFF8024_CTOR_SG:              "SG",
+	SFF8024_CTOR_OPT_PT:          "Optical pigtail",
+	SFF8024_CTOR_MPO:             "MPO Parallel Optic",
+	SFF8024_CTOR_MPO_2:           "MPO Parallel Optic - 2x16",
+	SFF8024_CTOR_HSDC_II:         "HSSDC II",
+	SFF8024_CTOR_COPPER_PT:       "Copper pigtail",
+	SFF8024_CTOR_RJ45:            "RJ45",
+	SFF8024_CTOR_NO_SEPARABLE:    "No separable connector",
+	SFF8024_CTOR_MXC_2x16:        "MXC 2x16",
+	SFF8024_CTOR_CS_OPTICAL:      "CS optical connector",
+	SFF8024_CTOR_CS_OPTICAL_MINI: "Mini CS optical connector",
+	SFF8024_CTOR_MPO_2X12:        "MPO 2x12",
+	SFF8024_CTOR_MPO_1X16:        "MPO 1x16",
+}
+
+// Encodings, see SFF-8024 table 4-2, some values depend on the module type
+const (
+	SFF8024_ENCODING_UNSPEC = 0x00
+	SFF8024_ENCODING_8B10B  = 0x01
+	SFF8024_ENCODING_4B5B   = 0x02
+	SFF8024_ENCODING_NRZ    = 0x03
+	SFF8024_ENCODING_4h     = 0x04
+	SFF8024_ENCODING_5h     = 0x05
+	SFF8024_ENCODING_6h     = 0x06
+	SFF8024_ENCODING_256B   = 0x07
+	SFF8024_ENCODING_PAM4   = 0x08
+)
+
+func sff8024ShowValue(v uint8, names map[uint8]string) string {
+	name, ok := names[v]
+	if !ok {
+		name = "reserved or unknown"
+	}
+	return fmt.Sprintf("0x%02x (%s)", v, name)
+}
+
+// sff8024ShowIdentifier describes the given module identifier.
+func sff8024ShowIdentifier(id uint8) string {
+	return sff8024ShowValue(id, sff8024IdentifierNames)
+}
+
+// sff8024ShowConnector describes the given connector type.
+func sff8024ShowConnector(ctor uint8) string {
+	return sff8024ShowValue(ctor, sff8024ConnectorNames)
+}
+
+// SFPConnector is a SFF-8024 connector type, one of the SFF8024_CTOR_*
+// constants.
+type SFPConnector uint8
+
+// String describes the connector type, e.g. "0x07 (LC)".
+func (c SFPConnector) String() string {
+	return sff8024ShowConnector(uint8(c))
+}
+
+// Name returns the name of the connector type, e.g. "LC".
+func (c SFPConnector) Name() string {
+	if name, ok := sff8024ConnectorNames[uint8(c)]; ok {
+		return name
+	}
+	return "reserved or unknown"
+}
+
+type sfpConnectorJSON struct {
+	Code        uint8  `json:"code"`
+	Description string `json:"description"`
+}
+
+// MarshalJSON encodes the connector type as its code and its name.
+func (c SFPConnector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sfpConnectorJSON{Code: uint8(c), Description: c.Name()})
+}
+
+// UnmarshalJSON decodes a connector type encoded by MarshalJSON.
+func (c *SFPConnector) UnmarshalJSON(b []byte) error {
+	var v sfpConnectorJSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*c = SFPConnector(v.Code)
+	return nil
+}
+
+// sff8024ShowEncoding describes the given encoding, the meaning of some
+// values depends on whether the module is a SFF-8472 or a SFF-8636 one.
+func sff8024ShowEncoding(encoding uint8, sff8636 bool) string {
+	names := map[uint8]string{
+		SFF8024_ENCODING_UNSPEC: "unspecified",
+		SFF8024_ENCODING_8B10B:  "8B/10B",
+		SFF8024_ENCODING_4B5B:   "4B/5B",
+		SFF8024_ENCODING_NRZ:    "NRZ",
+		SFF8024_ENCODING_4h:     "Manchester",
+		SFF8024_ENCODING_5h:     "SONET Scrambled",
+		SFF8024_ENCODING_6h:     "64B/66B",
+		SFF8024_ENCODING_256B:   "256B/257B (transcoded FEC-enabled data)",
+		SFF8024_ENCODING_PAM4:   "PAM4",
+	}
+	if sff8636 {
+		names[SFF8024_ENCODING_4h] = "SONET Scrambled"
+		names[SFF8024_ENCODING_5h] = "64B/66B"
+		names[SFF8024_ENCODING_6h] = "Manchester"
+	}
+	return sff8024ShowValue(encoding, names)
+}
+
+// sff8024ShowASCII returns the given space padded EEPROM string.
+func sff8024ShowASCII(b []byte) string {
+	return strings.TrimRight(goString(b), " ")
+}
+
+// sff8024ShowOUI returns the given vendor IEEE company ID.
+func sff8024ShowOUI(b []byte) string {
+	return fmt.Sprintf("%02x:%02x:%02x", b[0], b[1], b[2])
+}
+
+// sff8024ParseDate returns the manufacturing date and the vendor lot code
+// of the given date code, made of a YYMMDD date and an optional two
+// characters lot code. The zero time is returned for an invalid date.
+func sff8024ParseDate(b []byte) (time.Time, string) {
+	date, err := time.Parse("060102", string(b[:6]))
+	if err != nil {
+		date = time.Time{}
+	}
+	return date, sff8024ShowASCII(b[6:8])
+}
@@ -0,0 +1,209 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// SFF-8079 page A0h offsets, shared by SFF-8472
+const (
+	SFF8079_ID_OFFSET            = 0x00
+	SFF8079_EXT_ID_OFFSET        = 0x01
+	SFF8079_CTOR_OFFSET          = 0x02
+	SFF8079_10G_COMP_OFFSET      = 0x03
+	SFF8079_ETHERNET_COMP_OFFSET = 0x06
+	SFF8079_ENCODING_OFFSET      = 0x0b
+	SFF8079_BR_NOMINAL_OFFSET    = 0x0c
+	SFF8079_SMF_KM_LEN_OFFSET    = 0x0e
+	SFF8079_SMF_LEN_OFFSET       = 0x0f
+	SFF8079_OM2_LEN_OFFSET       = 0x10
+	SFF8079_OM1_LEN_OFFSET       = 0x11
+	SFF8079_CBL_LEN_OFFSET       = 0x12
+	SFF8079_OM3_LEN_OFFSET       = 0x13
+	SFF8079_VENDOR_NAME_START    = 0x14
+	SFF8079_VENDOR_NAME_END      = 0x23
+	SFF8079_EXT_COMP_OFFSET      = 0x24
+	SFF8079_VENDOR_OUI_OFFSET    = 0x25
+	SFF8079_VENDOR_PN_START      = 0x28
+	SFF8079_VENDOR_PN_END        = 0x37
+	SFF8079_VENDOR_REV_START     = 0x38
+	SFF8079_VENDOR_REV_END       = 0x3b
+	SFF8079_WAVELENGTH_OFFSET    = 0x3c
+	SFF8079_CC_BASE_OFFSET       = 0x3f
+	SFF8079_VENDOR_SN_START      = 0x44
+	SFF8079_VENDOR_SN_END        = 0x53
+	SFF8079_DATE_START           = 0x54
+	SFF8079_DATE_END             = 0x5b
+	SFF8079_CC_EXT_OFFSET        = 0x5f
+)
+
+// Compliance codes of bytes 3 and 6
+var sff8079Compliances = []struct {
+	offset int
+	bit    uint8
+	name   string
+}{
+	{SFF8079_10G_COMP_OFFSET, 1 << 7, "10G Ethernet: 10G Base-ER"},
+	{SFF8079_10G_COMP_OFFSET, 1 << 6, "10G Ethernet: 10G Base-LRM"},
+	{SFF8079_10G_COMP_OFFSET, 1 << 5, "10G Ethernet: 10G Base-LR"},
+	{SFF8079_10G_COMP_OFFSET, 1 << 4, "10G Ethernet: 10G Base-SR"},
+	{SFF8079_ETHERNET_COMP_OFFSET, 1 << 7, "Ethernet: BASE-PX"},
+	{SFF8079_ETHERNET_COMP_OFFSET, 1 << 6, "Ethernet: BASE-BX10"},
+	{SFF8079_ETHERNET_COMP_OFFSET, 1 << 5, "Ethernet: 100BASE-FX"},
+	{SFF8079_ETHERNET_COMP_OFFSET, 1 << 4, "Ethernet: 100BASE-LX/LX10"},
+	{SFF8079_ETHERNET_COMP_OFFSET, 1 << 3, "Ethernet: 1000BASE-T"},
+	{SFF8079_ETHERNET_COMP_OFFSET, 1 << 2, "Ethernet: 1000BASE-CX"},
+	{SFF8079_ETHERNET_COMP_OFFSET, 1 << 1, "Ethernet: 1000BASE-LX"},
+	{SFF8079_ETHERNET_COMP_OFFSET, 1 << 0, "Ethernet: 1000BASE-SX"},
+}
+
+// SFF8079CableLengths contains the link lengths supported by a module.
+type SFF8079CableLengths struct {
+	SMFkm      uint32 `json:"smf_km"`       // single mode fiber, in kilometers
+	SMFm       uint32 `json:"smf_m"`        // single mode fiber, in meters
+	OM2m       uint32 `json:"om2_m"`        // 50/125um OM2 fiber, in meters
+	OM1m       uint32 `json:"om1_m"`        // 62.5/125um OM1 fiber, in meters
+	CopperOM4m uint32 `json:"copper_om4_m"` // copper or OM4 fiber, in meters
+	OM3m       uint32 `json:"om3_m"`        // 50/125um OM3 fiber, in meters
+}
+
+// SFF8079 contains the identification of a SFP module, see SFF-8079.
+type SFF8079 struct {
+	Identifier      string              `json:"identifier"`
+	ExtIdentifier   uint8               `json:"ext_identifier"`
+	Connector       SFPConnector        `json:"connector"`
+	TransceiverType []string            `json:"transceiver_type,omitempty"`
+	Encoding        string              `json:"encoding,omitempty"`
+	BRNominalMbps   uint32              `json:"br_nominal_mbps"`
+	CableLengths    SFF8079CableLengths `json:"cable_lengths"`
+	WavelengthNm    uint16              `json:"wavelength_nm"` // laser wavelength, copper cable compliance otherwise
+	VendorName      string              `json:"vendor_name,omitempty"`
+	VendorOUI       string              `json:"vendor_oui,omitempty"`
+	VendorPN        string              `json:"vendor_pn,omitempty"`
+	VendorSN        string              `json:"vendor_sn,omitempty"`
+	VendorRev       string              `json:"vendor_rev,omitempty"`
+	VendorDate      time.Time           `json:"vendor_date"` // zero if the date code is invalid
+	VendorLot       string              `json:"vendor_lot,omitempty"`
+}
+
+func sff8079ShowTransceiver(id []byte) []string {
+	var types []string
+
+	for _, comp := range sff8079Compliances {
+		if id[comp.offset]&comp.bit != 0 {
+			types = append(types, comp.name)
+		}
+	}
+
+	// extended specification compliance, see SFF-8024 table 4-4
+	if ext := id[SFF8079_EXT_COMP_OFFSET]; ext != 0 {
+		if name, ok := sff8636ExtendedCompliances[ext]; ok {
+			types = append(types, name)
+		} else {
+			types = append(types, fmt.Sprintf("extended compliance 0x%02x", ext))
+		}
+	}
+
+	return types
+}
+
+func sff8079ShowCableLengths(id []byte) SFF8079CableLengths {
+	return SFF8079CableLengths{
+		SMFkm:      uint32(id[SFF8079_SMF_KM_LEN_OFFSET]),
+		SMFm:       uint32(id[SFF8079_SMF_LEN_OFFSET]) * 100,
+		OM2m:       uint32(id[SFF8079_OM2_LEN_OFFSET]) * 10,
+		OM1m:       uint32(id[SFF8079_OM1_LEN_OFFSET]) * 10,
+		CopperOM4m: uint32(id[SFF8079_CBL_LEN_OFFSET]),
+		OM3m:       uint32(id[SFF8079_OM3_LEN_OFFSET]) * 10,
+	}
+}
+
+// sff8079Checksum returns the low order 8 bits of the sum of the given
+// bytes.
+func sff8079Checksum(b []byte) uint8 {
+	var sum uint8
+	for _, v := range b {
+		sum += v
+	}
+	return sum
+}
+
+// ValidateSFF8079Checksum verifies the CC_BASE checksum of the base ID
+// fields and the CC_EXT checksum of the extended ID fields of the given SFP
+// module EEPROM.
+func ValidateSFF8079Checksum(id []byte) error {
+	if len(id) < SFF8079_CC_EXT_OFFSET+1 {
+		return fmt.Errorf("SFF-8079 EEPROM too short: %d bytes", len(id))
+	}
+
+	if sum := sff8079Checksum(id[:SFF8079_CC_BASE_OFFSET]); sum != id[SFF8079_CC_BASE_OFFSET] {
+		return fmt.Errorf("invalid SFF-8079 CC_BASE checksum: expected 0x%02x, found 0x%02x", sum, id[SFF8079_CC_BASE_OFFSET])
+	}
+
+	if sum := sff8079Checksum(id[SFF8079_CC_BASE_OFFSET+1 : SFF8079_CC_EXT_OFFSET]); sum != id[SFF8079_CC_EXT_OFFSET] {
+		return fmt.Errorf("invalid SFF-8079 CC_EXT checksum: expected 0x%02x, found 0x%02x", sum, id[SFF8079_CC_EXT_OFFSET])
+	}
+
+	return nil
+}
+
+// ParseSFF8079 decodes the identification of the given SFP module EEPROM
+// after having verified its checksums, see ValidateSFF8079Checksum.
+func ParseSFF8079(id []byte) (*SFF8079, error) {
+	if err := ValidateSFF8079Checksum(id); err != nil {
+		return nil, err
+	}
+
+	return ParseSFF8079Unchecked(id)
+}
+
+// ParseSFF8079Unchecked decodes the identification of the given SFP module
+// EEPROM without verifying its checksums. Only the bytes up to the date
+// code are required, allowing to decode partial reads.
+func ParseSFF8079Unchecked(id []byte) (*SFF8079, error) {
+	if len(id) < SFF8079_DATE_END+1 {
+		return nil, fmt.Errorf("SFF-8079 EEPROM too short: %d bytes", len(id))
+	}
+
+	date, lot := sff8024ParseDate(id[SFF8079_DATE_START : SFF8079_DATE_END+1])
+
+	return &SFF8079{
+		Identifier:      sff8024ShowIdentifier(id[SFF8079_ID_OFFSET]),
+		ExtIdentifier:   id[SFF8079_EXT_ID_OFFSET],
+		Connector:       SFPConnector(id[SFF8079_CTOR_OFFSET]),
+		TransceiverType: sff8079ShowTransceiver(id),
+		Encoding:        sff8024ShowEncoding(id[SFF8079_ENCODING_OFFSET], false),
+		BRNominalMbps:   uint32(id[SFF8079_BR_NOMINAL_OFFSET]) * 100,
+		CableLengths:    sff8079ShowCableLengths(id),
+		WavelengthNm:    binary.BigEndian.Uint16(id[SFF8079_WAVELENGTH_OFFSET:]),
+		VendorName:      sff8024ShowASCII(id[SFF8079_VENDOR_NAME_START : SFF8079_VENDOR_NAME_END+1]),
+		VendorOUI:       sff8024ShowOUI(id[SFF8079_VENDOR_OUI_OFFSET:]),
+		VendorPN:        sff8024ShowASCII(id[SFF8079_VENDOR_PN_START : SFF8079_VENDOR_PN_END+1]),
+		VendorSN:        sff8024ShowASCII(id[SFF8079_VENDOR_SN_START : SFF8079_VENDOR_SN_END+1]),
+		VendorRev:       sff8024ShowASCII(id[SFF8079_VENDOR_REV_START : SFF8079_VENDOR_REV_END+1]),
+		VendorDate:      date,
+		VendorLot:       lot,
+	}, nil
+}
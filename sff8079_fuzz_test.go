@@ -0,0 +1,49 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"testing"
+)
+
+func FuzzParseSFF8079(f *testing.F) {
+	id := newSFF8079EEPROM()
+	for _, l := range []int{0, 1, SFF8079_DATE_END, SFF8079_DATE_END + 1, len(id)} {
+		f.Add(id[:l])
+	}
+
+	f.Fuzz(func(t *testing.T, id []byte) {
+		ParseSFF8079(id)
+
+		sff, err := ParseSFF8079Unchecked(id)
+		if len(id) < SFF8079_DATE_END+1 {
+			if err == nil || sff != nil {
+				t.Errorf("expected an error for a %d bytes EEPROM", len(id))
+			}
+		} else if err != nil {
+			t.Errorf("unexpected error for a %d bytes EEPROM: %s", len(id), err)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSFF8079EEPROM() []byte {
+	id := make([]byte, ETH_MODULE_SFF_8079_LEN)
+	id[SFF8079_ID_OFFSET] = SFF8024_ID_SFP
+	id[SFF8079_EXT_ID_OFFSET] = 0x04
+	id[SFF8079_CTOR_OFFSET] = SFF8024_CTOR_LC
+	id[SFF8079_10G_COMP_OFFSET] = 1 << 4
+	id[SFF8079_ENCODING_OFFSET] = SFF8024_ENCODING_6h
+	id[SFF8079_BR_NOMINAL_OFFSET] = 103
+	id[SFF8079_OM2_LEN_OFFSET] = 8
+	id[SFF8079_OM1_LEN_OFFSET] = 3
+	id[SFF8079_OM3_LEN_OFFSET] = 30
+	copy(id[SFF8079_VENDOR_NAME_START:], "ACME CORP.      ")
+	copy(id[SFF8079_VENDOR_OUI_OFFSET:], []byte{0x00, 0x90, 0x65})
+	copy(id[SFF8079_VENDOR_PN_START:], "SFP-10G-SR      ")
+	copy(id[SFF8079_VENDOR_REV_START:], "A   ")
+	id[SFF8079_WAVELENGTH_OFFSET] = 0x03
+	id[SFF8079_WAVELENGTH_OFFSET+1] = 0x52
+	copy(id[SFF8079_VENDOR_SN_START:], "SN0123456789    ")
+	copy(id[SFF8079_DATE_START:], "190704  ")
+	setSFF8079Checksums(id)
+	return id
+}
+
+func setSFF8079Checksums(id []byte) {
+	id[SFF8079_CC_BASE_OFFSET] = sff8079Checksum(id[:SFF8079_CC_BASE_OFFSET])
+	id[SFF8079_CC_EXT_OFFSET] = sff8079Checksum(id[SFF8079_CC_BASE_OFFSET+1 : SFF8079_CC_EXT_OFFSET])
+}
+
+func TestParseSFF8079(t *testing.T) {
+	sff, err := ParseSFF8079(newSFF8079EEPROM())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &SFF8079{
+		Identifier:      "0x03 (SFP)",
+		ExtIdentifier:   0x04,
+		Connector:       SFF8024_CTOR_LC,
+		TransceiverType: []string{"10G Ethernet: 10G Base-SR"},
+		Encoding:        "0x06 (64B/66B)",
+		BRNominalMbps:   10300,
+		CableLengths:    SFF8079CableLengths{OM2m: 80, OM1m: 30, OM3m: 300},
+		WavelengthNm:    850,
+		VendorName:      "ACME CORP.",
+		VendorOUI:       "00:90:65",
+		VendorPN:        "SFP-10G-SR",
+		VendorSN:        "SN0123456789",
+		VendorRev:       "A",
+		VendorDate:      time.Date(2019, time.July, 4, 0, 0, 0, 0, time.UTC),
+	}
+
+	if !reflect.DeepEqual(sff, expected) {
+		t.Errorf("expected %+v, got %+v", expected, sff)
+	}
+}
+
+func TestSFF8079VendorDate(t *testing.T) {
+	tests := []struct {
+		code string
+		date time.Time
+		lot  string
+	}{
+		{"190704  ", time.Date(2019, time.July, 4, 0, 0, 0, 0, time.UTC), ""},
+		{"21123101", time.Date(2021, time.December, 31, 0, 0, 0, 0, time.UTC), "01"},
+		{"991231AB", time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC), "AB"},
+		{"200230  ", time.Time{}, ""},
+		{"        ", time.Time{}, ""},
+		{"\x00\x00\x00\x00\x00\x00\x00\x00", time.Time{}, ""},
+	}
+
+	for _, test := range tests {
+		id := newSFF8079EEPROM()
+		copy(id[SFF8079_DATE_START:], test.code)
+		setSFF8079Checksums(id)
+
+		sff, err := ParseSFF8079(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sff.VendorDate.Equal(test.date) || sff.VendorLot != test.lot {
+			t.Errorf("%q: expected %s %q, got %s %q", test.code, test.date, test.lot, sff.VendorDate, sff.VendorLot)
+		}
+	}
+}
+
+func TestValidateSFF8079Checksum(t *testing.T) {
+	id := newSFF8079EEPROM()
+	if err := ValidateSFF8079Checksum(id); err != nil {
+		t.Fatal(err)
+	}
+
+	id[SFF8079_VENDOR_NAME_START] = 'a'
+	if err := ValidateSFF8079Checksum(id); err == nil || !strings.Contains(err.Error(), "CC_BASE") {
+		t.Errorf("expected a CC_BASE checksum error, got %v", err)
+	}
+	if _, err := ParseSFF8079(id); err == nil {
+		t.Error("expected ParseSFF8079 to fail")
+	}
+	if _, err := ParseSFF8079Unchecked(id); err != nil {
+		t.Errorf("unexpected ParseSFF8079Unchecked error: %s", err)
+	}
+
+	id = newSFF8079EEPROM()
+	id[SFF8079_VENDOR_SN_START] = 'a'
+	if err := ValidateSFF8079Checksum(id); err == nil || !strings.Contains(err.Error(), "CC_EXT") {
+		t.Errorf("expected a CC_EXT checksum error, got %v", err)
+	}
+
+	if err := ValidateSFF8079Checksum(id[:SFF8079_CC_EXT_OFFSET]); err == nil {
+		t.Error("expected an error for a short EEPROM")
+	}
+}
+
+func TestSFPConnector(t *testing.T) {
+	c := SFPConnector(SFF8024_CTOR_LC)
+	if c.String() != "0x07 (LC)" || c.Name() != "LC" {
+		t.Errorf("unexpected connector description %s, %s", c, c.Name())
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"code":7,"description":"LC"}` {
+		t.Errorf("unexpected JSON %s", b)
+	}
+
+	var decoded SFPConnector
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != c {
+		t.Errorf("expected %s, got %s", c, decoded)
+	}
+}
+
+func TestDetectModuleType(t *testing.T) {
+	for id, expected := range map[uint8]string{
+		SFF8024_ID_SFP:            MODULE_TYPE_SFF_8079,
+		SFF8024_ID_DWDM_SFP:       MODULE_TYPE_SFF_8079,
+		SFF8024_ID_QSFP:           MODULE_TYPE_SFF_8636,
+		SFF8024_ID_QSFP_PLUS:      MODULE_TYPE_SFF_8636,
+		SFF8024_ID_QSFP28:         MODULE_TYPE_SFF_8636,
+		SFF8024_ID_QSFP_DD:        MODULE_TYPE_CMIS,
+		SFF8024_ID_OSFP:           MODULE_TYPE_CMIS,
+		SFF8024_ID_QSFP_PLUS_CMIS: MODULE_TYPE_CMIS,
+	} {
+		moduleType, err := DetectModuleType([]byte{id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if moduleType != expected {
+			t.Errorf("expected %s for identifier 0x%02x, got %s", expected, id, moduleType)
+		}
+	}
+
+	for _, id := range [][]byte{nil, {SFF8024_ID_UNKNOWN}, {SFF8024_ID_XFP}} {
+		if _, err := DetectModuleType(id); err == nil {
+			t.Errorf("expected an error for %v", id)
+		}
+	}
+}
+
+func TestParseModuleEeprom(t *testing.T) {
+	cmis := make([]byte, CMIS_PAGE_LEN)
+	cmis[CMIS_ID_OFFSET] = SFF8024_ID_OSFP
+
+	tests := []struct {
+		id         []byte
+		moduleType string
+	}{
+		{newSFF8079EEPROM(), "SFF-8079"},
+		{newSFF8636EEPROM(), "SFF-8636"},
+		{cmis, "CMIS"},
+	}
+
+	for _, test := range tests {
+		m, err := ParseModuleEeprom(test.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.ModuleType() != test.moduleType {
+			t.Errorf("expected module type %s, got %s", test.moduleType, m.ModuleType())
+		}
+	}
+
+	for _, id := range [][]byte{nil, {SFF8024_ID_SFP}, {SFF8024_ID_XFP}} {
+		if m, err := ParseModuleEeprom(id); err == nil || m != nil {
+			t.Errorf("expected an error and no module for %v, got %v", id, m)
+		}
+	}
+}
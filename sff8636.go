@@ -0,0 +1,275 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"fmt"
+	"time"
+)
+
+// SFF-8636 lower and upper page 00h offsets
+const (
+	SFF8636_ID_OFFSET                  = 0x00
+	SFF8636_REV_COMPLIANCE_OFFSET      = 0x01
+	SFF8636_PWR_MODE_OFFSET            = 0x5d
+	SFF8636_UPPER_ID_OFFSET            = 0x80
+	SFF8636_EXT_ID_OFFSET              = 0x81
+	SFF8636_CTOR_OFFSET                = 0x82
+	SFF8636_ETHERNET_COMP_OFFSET       = 0x83
+	SFF8636_ENCODING_OFFSET            = 0x8b
+	SFF8636_BR_NOMINAL_OFFSET          = 0x8c
+	SFF8636_SM_LEN_OFFSET              = 0x8e
+	SFF8636_OM3_LEN_OFFSET             = 0x8f
+	SFF8636_OM2_LEN_OFFSET             = 0x90
+	SFF8636_OM1_LEN_OFFSET             = 0x91
+	SFF8636_CBL_LEN_OFFSET             = 0x92
+	SFF8636_VENDOR_NAME_START_OFFSET   = 0x94
+	SFF8636_VENDOR_NAME_END_OFFSET     = 0xa3
+	SFF8636_VENDOR_OUI_OFFSET          = 0xa5
+	SFF8636_VENDOR_PN_START_OFFSET     = 0xa8
+	SFF8636_VENDOR_PN_END_OFFSET       = 0xb7
+	SFF8636_VENDOR_REV_START_OFFSET    = 0xb8
+	SFF8636_VENDOR_REV_END_OFFSET      = 0xb9
+	SFF8636_OPTION_1_OFFSET            = 0xc0
+	SFF8636_VENDOR_SN_START_OFFSET     = 0xc4
+	SFF8636_VENDOR_SN_END_OFFSET       = 0xd3
+	SFF8636_DATE_YEAR_OFFSET           = 0xd4
+	SFF8636_DATE_VENDOR_LOT_END_OFFSET = 0xdb
+	SFF8636_BR_NOMINAL_EXT_OFFSET      = 0xde
+)
+
+// power control bits of byte 93
+const (
+	SFF8636_LOW_PWR_OVERRIDE = 1 << 0
+	SFF8636_LOW_PWR_SET      = 1 << 1
+	SFF8636_HIGH_PWR_ENABLE  = 1 << 2
+)
+
+// Ethernet compliance codes of byte 131
+var sff8636EthernetCompliances = []struct {
+	bit  uint8
+	name string
+}{
+	{1 << 0, "40G Active Cable (XLPPI)"},
+	{1 << 1, "40GBASE-LR4"},
+	{1 << 2, "40GBASE-SR4"},
+	{1 << 3, "40GBASE-CR4"},
+	{1 << 4, "10GBASE-SR"},
+	{1 << 5, "10GBASE-LR"},
+	{1 << 6, "10GBASE-LRM"},
+}
+
+// extended compliance codes of byte 192, see SFF-8024 table 4-4
+var sff8636ExtendedCompliances = map[uint8]string{
+	0x01: "100G AOC or 25GAUI C2M AOC with worst BER of 5x10^(-5)",
+	0x02: "100GBASE-SR4 or 25GBASE-SR",
+	0x03: "100GBASE-LR4 or 25GBASE-LR",
+	0x04: "100GBASE-ER4 or 25GBASE-ER",
+	0x05: "100GBASE-SR10",
+	0x06: "100G CWDM4",
+	0x07: "100G PSM4 Parallel SMF",
+	0x08: "100G ACC or 25GAUI C2M ACC with worst BER of 5x10^(-5)",
+	0x0b: "100GBASE-CR4 or 25GBASE-CR CA-L",
+	0x0c: "25GBASE-CR CA-S",
+	0x0d: "25GBASE-CR CA-N",
+	0x10: "40GBASE-ER4",
+	0x11: "4 x 10GBASE-SR",
+	0x12: "40G PSM4 Parallel SMF",
+	0x16: "10GBASE-T with SFI electrical interface",
+	0x17: "100G CLR4",
+	0x18: "100G AOC or 25GAUI C2M AOC with worst BER of 10^(-12)",
+	0x19: "100G ACC or 25GAUI C2M ACC with worst BER of 10^(-12)",
+	0x1a: "100GE-DWDM2",
+	0x1c: "10GBASE-T Short Reach",
+	0x20: "100G SWDM4",
+	0x21: "100G PAM4 BiDi",
+	0x25: "100GBASE-DR",
+	0x26: "100G-FR or 100GBASE-FR1",
+	0x27: "100G-LR or 100GBASE-LR1",
+}
+
+// SFF8636CableLengths contains the link lengths supported by a module.
+type SFF8636CableLengths struct {
+	SMFkm      uint32 `json:"smf_km"`       // single mode fiber, in kilometers
+	OM3m       uint32 `json:"om3_m"`        // 50/125um OM3 fiber, in meters
+	OM2m       uint32 `json:"om2_m"`        // 50/125um OM2 fiber, in meters
+	OM1m       uint32 `json:"om1_m"`        // 62.5/125um OM1 fiber, in meters
+	CopperOM4m uint32 `json:"copper_om4_m"` // copper or OM4 fiber, in meters
+}
+
+// SFF8636 contains the identification of a QSFP module, see SFF-8636.
+type SFF8636 struct {
+	Identifier         string              `json:"identifier"`
+	RevCompliance      uint8               `json:"rev_compliance"`
+	ExtIdentifier      uint8               `json:"ext_identifier"`
+	ExtIdentifierDescr []string            `json:"ext_identifier_descr,omitempty"`
+	HighPowerEnabled   bool                `json:"high_power_enabled"` // power classes 5 to 7 enabled by the host
+	Connector          string              `json:"connector,omitempty"`
+	TransceiverType    []string            `json:"transceiver_type,omitempty"`
+	Encoding           string              `json:"encoding,omitempty"`
+	BRNominalMbps      uint32              `json:"br_nominal_mbps"`
+	CableLengths       SFF8636CableLengths `json:"cable_lengths"`
+	VendorName         string              `json:"vendor_name,omitempty"`
+	VendorOUI          string              `json:"vendor_oui,omitempty"`
+	VendorPN           string              `json:"vendor_pn,omitempty"`
+	VendorSN           string              `json:"vendor_sn,omitempty"`
+	VendorRev          string              `json:"vendor_rev,omitempty"`
+	VendorDate         time.Time           `json:"vendor_date"` // zero if the date code is invalid
+	VendorLot          string              `json:"vendor_lot,omitempty"`
+}
+
+// maximum power consumption of the power classes 1 to 7, in watts
+var sff8636PowerClassesW = [...]float64{1.5, 2.0, 2.5, 3.5, 4.0, 4.5, 5.0}
+
+// PowerClass returns the power class of the module, from 1 to 7, as
+// advertised by the extended identifier. The classes above 4 are only
+// used when HighPowerEnabled is set.
+func (s *SFF8636) PowerClass() int {
+	if highPower := s.ExtIdentifier & 0x03; highPower != 0 {
+		return 4 + int(highPower)
+	}
+	return 1 + int(s.ExtIdentifier>>6)
+}
+
+// PowerConsumptionW returns the maximum power consumption of the power
+// class of the module, in watts.
+func (s *SFF8636) PowerConsumptionW() float64 {
+	return sff8636PowerClassesW[s.PowerClass()-1]
+}
+
+// power classes of the bits 7-6 and 1-0 of the extended identifier
+var sff8636PowerClasses = [...]string{
+	"1.5W max. Power consumption",
+	"2.0W max. Power consumption",
+	"2.5W max. Power consumption",
+	"3.5W max. Power consumption",
+}
+
+var sff8636HighPowerClasses = [...]string{
+	"",
+	"4.0W max. Power consumption",
+	"4.5W max. Power consumption",
+	"5.0W max. Power consumption",
+}
+
+func sff8636ShowExtIdentifierDescr(id []byte) []string {
+	ext := id[SFF8636_EXT_ID_OFFSET]
+
+	descr := []string{sff8636PowerClasses[ext>>6]}
+	if highPower := sff8636HighPowerClasses[ext&0x03]; highPower != "" {
+		descr = append(descr, highPower)
+	}
+
+	if ext&(1<<4) != 0 {
+		descr = append(descr, "CLEI code present in Page 02h")
+	} else {
+		descr = append(descr, "No CLEI code present in Page 02h")
+	}
+	if ext&(1<<3) != 0 {
+		descr = append(descr, "CDR present in TX")
+	} else {
+		descr = append(descr, "No CDR in TX")
+	}
+	if ext&(1<<2) != 0 {
+		descr = append(descr, "CDR present in RX")
+	} else {
+		descr = append(descr, "No CDR in RX")
+	}
+
+	if id[SFF8636_PWR_MODE_OFFSET]&SFF8636_HIGH_PWR_ENABLE != 0 {
+		descr = append(descr, "High Power Class (> 3.5 W) enabled")
+	} else {
+		descr = append(descr, "High Power Class (> 3.5 W) not enabled")
+	}
+
+	return descr
+}
+
+func sff8636ShowTransceiver(id []byte) []string {
+	var types []string
+
+	eth := id[SFF8636_ETHERNET_COMP_OFFSET]
+	for _, comp := range sff8636EthernetCompliances {
+		if eth&comp.bit != 0 {
+			types = append(types, comp.name)
+		}
+	}
+
+	// extended specification compliance, see byte 192
+	if eth&(1<<7) != 0 {
+		if name, ok := sff8636ExtendedCompliances[id[SFF8636_OPTION_1_OFFSET]]; ok {
+			types = append(types, name)
+		} else {
+			types = append(types, fmt.Sprintf("extended compliance 0x%02x", id[SFF8636_OPTION_1_OFFSET]))
+		}
+	}
+
+	return types
+}
+
+func sff8636ShowBRNominal(id []byte) uint32 {
+	// above 25.4 Gbps the nominal rate is in units of 250 Mbps
+	if br := id[SFF8636_BR_NOMINAL_OFFSET]; br != 0xff {
+		return uint32(br) * 100
+	}
+	return uint32(id[SFF8636_BR_NOMINAL_EXT_OFFSET]) * 250
+}
+
+func sff8636ShowCableLengths(id []byte) SFF8636CableLengths {
+	return SFF8636CableLengths{
+		SMFkm:      uint32(id[SFF8636_SM_LEN_OFFSET]),
+		OM3m:       uint32(id[SFF8636_OM3_LEN_OFFSET]) * 2,
+		OM2m:       uint32(id[SFF8636_OM2_LEN_OFFSET]),
+		OM1m:       uint32(id[SFF8636_OM1_LEN_OFFSET]),
+		CopperOM4m: uint32(id[SFF8636_CBL_LEN_OFFSET]),
+	}
+}
+
+// ParseSFF8636 decodes the identification of the given QSFP module EEPROM,
+// made at least of the lower page and of the upper page 00h.
+func ParseSFF8636(id []byte) (*SFF8636, error) {
+	if len(id) < ETH_MODULE_SFF_8636_LEN {
+		return nil, fmt.Errorf("SFF-8636 EEPROM too short: %d bytes", len(id))
+	}
+
+	date, lot := sff8024ParseDate(id[SFF8636_DATE_YEAR_OFFSET : SFF8636_DATE_VENDOR_LOT_END_OFFSET+1])
+
+	return &SFF8636{
+		Identifier:         sff8024ShowIdentifier(id[SFF8636_ID_OFFSET]),
+		RevCompliance:      id[SFF8636_REV_COMPLIANCE_OFFSET],
+		ExtIdentifier:      id[SFF8636_EXT_ID_OFFSET],
+		ExtIdentifierDescr: sff8636ShowExtIdentifierDescr(id),
+		HighPowerEnabled:   id[SFF8636_PWR_MODE_OFFSET]&SFF8636_HIGH_PWR_ENABLE != 0,
+		Connector:          sff8024ShowConnector(id[SFF8636_CTOR_OFFSET]),
+		TransceiverType:    sff8636ShowTransceiver(id),
+		Encoding:           sff8024ShowEncoding(id[SFF8636_ENCODING_OFFSET], true),
+		BRNominalMbps:      sff8636ShowBRNominal(id),
+		CableLengths:       sff8636ShowCableLengths(id),
+		VendorName:         sff8024ShowASCII(id[SFF8636_VENDOR_NAME_START_OFFSET : SFF8636_VENDOR_NAME_END_OFFSET+1]),
+		VendorOUI:          sff8024ShowOUI(id[SFF8636_VENDOR_OUI_OFFSET:]),
+		VendorPN:           sff8024ShowASCII(id[SFF8636_VENDOR_PN_START_OFFSET : SFF8636_VENDOR_PN_END_OFFSET+1]),
+		VendorSN:           sff8024ShowASCII(id[SFF8636_VENDOR_SN_START_OFFSET : SFF8636_VENDOR_SN_END_OFFSET+1]),
+		VendorRev:          sff8024ShowASCII(id[SFF8636_VENDOR_REV_START_OFFSET : SFF8636_VENDOR_REV_END_OFFSET+1]),
+		VendorDate:         date,
+		VendorLot:          lot,
+	}, nil
+}
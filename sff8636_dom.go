@@ -0,0 +1,308 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// SFF-8636 lower page offsets of the digital diagnostics
+const (
+	SFF8636_LOS_AW_OFFSET        = 0x03
+	SFF8636_FAULT_AW_OFFSET      = 0x04
+	SFF8636_TEMP_AW_OFFSET       = 0x06
+	SFF8636_VCC_AW_OFFSET        = 0x07
+	SFF8636_RX_PWR_12_AW_OFFSET  = 0x09
+	SFF8636_RX_PWR_34_AW_OFFSET  = 0x0a
+	SFF8636_TX_BIAS_12_AW_OFFSET = 0x0b
+	SFF8636_TX_BIAS_34_AW_OFFSET = 0x0c
+	SFF8636_TX_PWR_12_AW_OFFSET  = 0x0d
+	SFF8636_TX_PWR_34_AW_OFFSET  = 0x0e
+	SFF8636_TEMP_CURR            = 0x16
+	SFF8636_VCC_CURR             = 0x1a
+	SFF8636_RX_PWR_1_OFFSET      = 0x22
+	SFF8636_TX_BIAS_1_OFFSET     = 0x2a
+	SFF8636_TX_PWR_1_OFFSET      = 0x32
+)
+
+// SFF-8636 upper page 03h offsets of the thresholds, the page follows the
+// lower page and the upper pages 00h to 02h in the module EEPROM dump
+const (
+	SFF8636_TEMP_HALRM     = 0x200
+	SFF8636_TEMP_LALRM     = 0x202
+	SFF8636_TEMP_HWARN     = 0x204
+	SFF8636_TEMP_LWARN     = 0x206
+	SFF8636_VCC_HALRM      = 0x210
+	SFF8636_VCC_LALRM      = 0x212
+	SFF8636_VCC_HWARN      = 0x214
+	SFF8636_VCC_LWARN      = 0x216
+	SFF8636_RX_PWR_HALRM   = 0x230
+	SFF8636_RX_PWR_LALRM   = 0x232
+	SFF8636_RX_PWR_HWARN   = 0x234
+	SFF8636_RX_PWR_LWARN   = 0x236
+	SFF8636_TX_BIAS_HALRM  = 0x238
+	SFF8636_TX_BIAS_LALRM  = 0x23a
+	SFF8636_TX_BIAS_HWARN  = 0x23c
+	SFF8636_TX_BIAS_LWARN  = 0x23e
+	SFF8636_TX_PWR_HALRM   = 0x240
+	SFF8636_TX_PWR_LALRM   = 0x242
+	SFF8636_TX_PWR_HWARN   = 0x244
+	SFF8636_TX_PWR_LWARN   = 0x246
+	SFF8636_THRESHOLDS_END = 0x248
+)
+
+// SFF8636DOMFlags contains the alarm or warning flags of the SFF-8636
+// digital diagnostics. Channel flags are bitmasks, bit 0 being the first
+// channel.
+type SFF8636DOMFlags struct {
+	TempHigh    bool  `json:"temp_high"`
+	TempLow     bool  `json:"temp_low"`
+	VccHigh     bool  `json:"vcc_high"`
+	VccLow      bool  `json:"vcc_low"`
+	RXPowerHigh uint8 `json:"rx_power_high"`
+	RXPowerLow  uint8 `json:"rx_power_low"`
+	TXBiasHigh  uint8 `json:"tx_bias_high"`
+	TXBiasLow   uint8 `json:"tx_bias_low"`
+	TXPowerHigh uint8 `json:"tx_power_high"`
+	TXPowerLow  uint8 `json:"tx_power_low"`
+}
+
+// SFF8636DOM contains the digital diagnostics of a QSFP module, see
+// SFF-8636.
+type SFF8636DOM struct {
+	Temperature    float64         `json:"temperature"`      // module temperature in degrees Celsius
+	Voltage        float64         `json:"voltage"`          // supply voltage in volts
+	ChannelTXPower [4]float64      `json:"channel_tx_power"` // transmitted optical power in milliwatts
+	ChannelRXPower [4]float64      `json:"channel_rx_power"` // received optical power in milliwatts
+	ChannelTXBias  [4]float64      `json:"channel_tx_bias"`  // laser bias current in milliamperes
+	Alarms         SFF8636DOMFlags `json:"alarms"`
+	Warnings       SFF8636DOMFlags `json:"warnings"`
+}
+
+// sff8636ChannelFlags returns the high and low channel bitmasks of the
+// flags stored in the given two bytes, each channel using a nibble made of
+// the high alarm, low alarm, high warning and low warning flags.
+func sff8636ChannelFlags(b []byte, shift uint) (high, low uint8) {
+	for channel := uint(0); channel < 4; channel++ {
+		nibble := b[channel/2] >> (4 * (1 - channel%2))
+		if nibble&(1<<(3-shift)) != 0 {
+			high |= 1 << channel
+		}
+		if nibble&(1<<(2-shift)) != 0 {
+			low |= 1 << channel
+		}
+	}
+	return
+}
+
+func decodeSFF8636DOMFlags(id []byte, warning bool) SFF8636DOMFlags {
+	// warnings come after the alarms
+	var shift uint
+	if warning {
+		shift = 1
+	}
+
+	flags := SFF8636DOMFlags{
+		TempHigh: id[SFF8636_TEMP_AW_OFFSET]&(1<<(7-2*shift)) != 0,
+		TempLow:  id[SFF8636_TEMP_AW_OFFSET]&(1<<(6-2*shift)) != 0,
+		VccHigh:  id[SFF8636_VCC_AW_OFFSET]&(1<<(7-2*shift)) != 0,
+		VccLow:   id[SFF8636_VCC_AW_OFFSET]&(1<<(6-2*shift)) != 0,
+	}
+	flags.RXPowerHigh, flags.RXPowerLow = sff8636ChannelFlags(id[SFF8636_RX_PWR_12_AW_OFFSET:], 2*shift)
+	flags.TXBiasHigh, flags.TXBiasLow = sff8636ChannelFlags(id[SFF8636_TX_BIAS_12_AW_OFFSET:], 2*shift)
+	flags.TXPowerHigh, flags.TXPowerLow = sff8636ChannelFlags(id[SFF8636_TX_PWR_12_AW_OFFSET:], 2*shift)
+
+	return flags
+}
+
+// ParseSFF8636DOM decodes the digital diagnostics of the given QSFP module
+// EEPROM lower page.
+func ParseSFF8636DOM(id []byte) (*SFF8636DOM, error) {
+	if len(id) < SFF8636_TX_PWR_1_OFFSET+8 {
+		return nil, fmt.Errorf("SFF-8636 EEPROM too short: %d bytes", len(id))
+	}
+
+	// values are in units of 1/256 degree, 100 uV, 0.1 uW and 2 uA
+	dom := &SFF8636DOM{
+		Temperature: float64(int16(binary.BigEndian.Uint16(id[SFF8636_TEMP_CURR:]))) / 256,
+		Voltage:     float64(binary.BigEndian.Uint16(id[SFF8636_VCC_CURR:])) * 100e-6,
+		Alarms:      decodeSFF8636DOMFlags(id, false),
+		Warnings:    decodeSFF8636DOMFlags(id, true),
+	}
+
+	for i := 0; i < 4; i++ {
+		dom.ChannelRXPower[i] = float64(binary.BigEndian.Uint16(id[SFF8636_RX_PWR_1_OFFSET+2*i:])) * 1e-4
+		dom.ChannelTXBias[i] = float64(binary.BigEndian.Uint16(id[SFF8636_TX_BIAS_1_OFFSET+2*i:])) * 2e-3
+		dom.ChannelTXPower[i] = float64(binary.BigEndian.Uint16(id[SFF8636_TX_PWR_1_OFFSET+2*i:])) * 1e-4
+	}
+
+	return dom, nil
+}
+
+// SFF8636ChannelDOM contains the digital diagnostics of a channel of a QSFP
+// module.
+type SFF8636ChannelDOM struct {
+	TXPowermW    float64 `json:"tx_power_mw"` // transmitted optical power in milliwatts
+	RXPowermW    float64 `json:"rx_power_mw"` // received optical power in milliwatts
+	TXBiasmA     float64 `json:"tx_bias_ma"`  // laser bias current in milliamperes
+	TXFault      bool    `json:"tx_fault"`
+	RXLos        bool    `json:"rx_los"` // loss of signal
+	TXPowerAlarm bool    `json:"tx_power_alarm"`
+	RXPowerAlarm bool    `json:"rx_power_alarm"`
+	TXBiasAlarm  bool    `json:"tx_bias_alarm"`
+}
+
+// ParseSFF8636ChannelMonitors decodes the digital diagnostics of each of
+// the 4 channels of the given QSFP module EEPROM lower page, along with
+// their fault, loss of signal and alarm flags.
+func ParseSFF8636ChannelMonitors(id []byte) ([4]SFF8636ChannelDOM, error) {
+	var channels [4]SFF8636ChannelDOM
+
+	dom, err := ParseSFF8636DOM(id)
+	if err != nil {
+		return channels, err
+	}
+
+	for i := range channels {
+		bit := uint8(1) << i
+		channels[i] = SFF8636ChannelDOM{
+			TXPowermW:    dom.ChannelTXPower[i],
+			RXPowermW:    dom.ChannelRXPower[i],
+			TXBiasmA:     dom.ChannelTXBias[i],
+			TXFault:      id[SFF8636_FAULT_AW_OFFSET]&bit != 0,
+			RXLos:        id[SFF8636_LOS_AW_OFFSET]&bit != 0,
+			TXPowerAlarm: (dom.Alarms.TXPowerHigh|dom.Alarms.TXPowerLow)&bit != 0,
+			RXPowerAlarm: (dom.Alarms.RXPowerHigh|dom.Alarms.RXPowerLow)&bit != 0,
+			TXBiasAlarm:  (dom.Alarms.TXBiasHigh|dom.Alarms.TXBiasLow)&bit != 0,
+		}
+	}
+
+	return channels, nil
+}
+
+// SFF8636Thresholds contains the alarm and warning thresholds of the
+// digital diagnostics of a QSFP module, in the units of SFF8636DOM.
+type SFF8636Thresholds struct {
+	TempHighAlarm    float64 `json:"temp_high_alarm"`
+	TempLowAlarm     float64 `json:"temp_low_alarm"`
+	TempHighWarn     float64 `json:"temp_high_warn"`
+	TempLowWarn      float64 `json:"temp_low_warn"`
+	VoltHighAlarm    float64 `json:"volt_high_alarm"`
+	VoltLowAlarm     float64 `json:"volt_low_alarm"`
+	VoltHighWarn     float64 `json:"volt_high_warn"`
+	VoltLowWarn      float64 `json:"volt_low_warn"`
+	RXPowerHighAlarm float64 `json:"rx_power_high_alarm"`
+	RXPowerLowAlarm  float64 `json:"rx_power_low_alarm"`
+	RXPowerHighWarn  float64 `json:"rx_power_high_warn"`
+	RXPowerLowWarn   float64 `json:"rx_power_low_warn"`
+	TXBiasHighAlarm  float64 `json:"tx_bias_high_alarm"`
+	TXBiasLowAlarm   float64 `json:"tx_bias_low_alarm"`
+	TXBiasHighWarn   float64 `json:"tx_bias_high_warn"`
+	TXBiasLowWarn    float64 `json:"tx_bias_low_warn"`
+	TXPowerHighAlarm float64 `json:"tx_power_high_alarm"`
+	TXPowerLowAlarm  float64 `json:"tx_power_low_alarm"`
+	TXPowerHighWarn  float64 `json:"tx_power_high_warn"`
+	TXPowerLowWarn   float64 `json:"tx_power_low_warn"`
+}
+
+// ParseSFF8636Thresholds decodes the thresholds of the digital diagnostics
+// of the given QSFP module EEPROM, which has to include the upper page 03h.
+func ParseSFF8636Thresholds(id []byte) (*SFF8636Thresholds, error) {
+	if len(id) < SFF8636_THRESHOLDS_END {
+		return nil, fmt.Errorf("SFF-8636 EEPROM too short for the thresholds: %d bytes", len(id))
+	}
+
+	temp := func(offset int) float64 {
+		return float64(int16(binary.BigEndian.Uint16(id[offset:]))) / 256
+	}
+	// units of 100 uV, 0.1 uW and 2 uA
+	value := func(offset int, unit float64) float64 {
+		return float64(binary.BigEndian.Uint16(id[offset:])) * unit
+	}
+
+	return &SFF8636Thresholds{
+		TempHighAlarm:    temp(SFF8636_TEMP_HALRM),
+		TempLowAlarm:     temp(SFF8636_TEMP_LALRM),
+		TempHighWarn:     temp(SFF8636_TEMP_HWARN),
+		TempLowWarn:      temp(SFF8636_TEMP_LWARN),
+		VoltHighAlarm:    value(SFF8636_VCC_HALRM, 100e-6),
+		VoltLowAlarm:     value(SFF8636_VCC_LALRM, 100e-6),
+		VoltHighWarn:     value(SFF8636_VCC_HWARN, 100e-6),
+		VoltLowWarn:      value(SFF8636_VCC_LWARN, 100e-6),
+		RXPowerHighAlarm: value(SFF8636_RX_PWR_HALRM, 1e-4),
+		RXPowerLowAlarm:  value(SFF8636_RX_PWR_LALRM, 1e-4),
+		RXPowerHighWarn:  value(SFF8636_RX_PWR_HWARN, 1e-4),
+		RXPowerLowWarn:   value(SFF8636_RX_PWR_LWARN, 1e-4),
+		TXBiasHighAlarm:  value(SFF8636_TX_BIAS_HALRM, 2e-3),
+		TXBiasLowAlarm:   value(SFF8636_TX_BIAS_LALRM, 2e-3),
+		TXBiasHighWarn:   value(SFF8636_TX_BIAS_HWARN, 2e-3),
+		TXBiasLowWarn:    value(SFF8636_TX_BIAS_LWARN, 2e-3),
+		TXPowerHighAlarm: value(SFF8636_TX_PWR_HALRM, 1e-4),
+		TXPowerLowAlarm:  value(SFF8636_TX_PWR_LALRM, 1e-4),
+		TXPowerHighWarn:  value(SFF8636_TX_PWR_HWARN, 1e-4),
+		TXPowerLowWarn:   value(SFF8636_TX_PWR_LWARN, 1e-4),
+	}, nil
+}
+
+// checkThreshold returns the violation, if any, of the given value,
+// alarms taking precedence over warnings.
+func checkThreshold(name, format string, value, highAlarm, lowAlarm, highWarn, lowWarn float64) (string, bool) {
+	var kind, verb string
+	var limit float64
+	switch {
+	case value > highAlarm:
+		kind, verb, limit = "high alarm", "exceeds", highAlarm
+	case value < lowAlarm:
+		kind, verb, limit = "low alarm", "below", lowAlarm
+	case value > highWarn:
+		kind, verb, limit = "high warning", "exceeds", highWarn
+	case value < lowWarn:
+		kind, verb, limit = "low warning", "below", lowWarn
+	default:
+		return "", false
+	}
+	return fmt.Sprintf("%s %s: "+format+" %s "+format, name, kind, value, verb, limit), true
+}
+
+// CheckThresholds returns the thresholds crossed by the diagnostics, e.g.
+// "temperature high alarm: 85.5°C exceeds 85.0°C". Only the alarm is
+// reported when both the alarm and the warning thresholds are crossed.
+func (d *SFF8636DOM) CheckThresholds(t *SFF8636Thresholds) []string {
+	var violations []string
+	check := func(name, format string, value, highAlarm, lowAlarm, highWarn, lowWarn float64) {
+		if violation, ok := checkThreshold(name, format, value, highAlarm, lowAlarm, highWarn, lowWarn); ok {
+			violations = append(violations, violation)
+		}
+	}
+
+	check("temperature", "%.1f°C", d.Temperature, t.TempHighAlarm, t.TempLowAlarm, t.TempHighWarn, t.TempLowWarn)
+	check("voltage", "%.2fV", d.Voltage, t.VoltHighAlarm, t.VoltLowAlarm, t.VoltHighWarn, t.VoltLowWarn)
+	for i := 0; i < 4; i++ {
+		channel := fmt.Sprintf("channel %d ", i+1)
+		check(channel+"rx power", "%.4fmW", d.ChannelRXPower[i], t.RXPowerHighAlarm, t.RXPowerLowAlarm, t.RXPowerHighWarn, t.RXPowerLowWarn)
+		check(channel+"tx bias", "%.3fmA", d.ChannelTXBias[i], t.TXBiasHighAlarm, t.TXBiasLowAlarm, t.TXBiasHighWarn, t.TXBiasLowWarn)
+		check(channel+"tx power", "%.4fmW", d.ChannelTXPower[i], t.TXPowerHighAlarm, t.TXPowerLowAlarm, t.TXPowerHighWarn, t.TXPowerLowWarn)
+	}
+
+	return violations
+}
@@ -0,0 +1,52 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"testing"
+)
+
+func FuzzParseSFF8636(f *testing.F) {
+	id := newSFF8636EEPROM()
+	for _, l := range []int{0, 1, SFF8079_DATE_END + 1, ETH_MODULE_SFF_8636_LEN - 1, ETH_MODULE_SFF_8636_LEN, len(id)} {
+		f.Add(id[:l])
+	}
+
+	f.Fuzz(func(t *testing.T, id []byte) {
+		ParseSFF8636DOM(id)
+		ParseSFF8636ChannelMonitors(id)
+		ParseSFF8636Thresholds(id)
+		ParseModuleEeprom(id)
+
+		sff, err := ParseSFF8636(id)
+		if len(id) < ETH_MODULE_SFF_8636_LEN {
+			if err == nil || sff != nil {
+				t.Errorf("expected an error for a %d bytes EEPROM", len(id))
+			}
+		} else if err != nil {
+			t.Errorf("unexpected error for a %d bytes EEPROM: %s", len(id), err)
+		}
+	})
+}
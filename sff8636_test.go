@@ -0,0 +1,277 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newSFF8636EEPROM() []byte {
+	id := make([]byte, ETH_MODULE_SFF_8636_MAX_LEN)
+	id[SFF8636_ID_OFFSET] = SFF8024_ID_QSFP28
+	id[SFF8636_UPPER_ID_OFFSET] = SFF8024_ID_QSFP28
+	id[SFF8636_EXT_ID_OFFSET] = 0xc0 | 1<<2 | 0x01
+	id[SFF8636_CTOR_OFFSET] = SFF8024_CTOR_MPO
+	id[SFF8636_ETHERNET_COMP_OFFSET] = 1 << 7
+	id[SFF8636_OPTION_1_OFFSET] = 0x02
+	id[SFF8636_ENCODING_OFFSET] = SFF8024_ENCODING_5h
+	id[SFF8636_BR_NOMINAL_OFFSET] = 0xff
+	id[SFF8636_BR_NOMINAL_EXT_OFFSET] = 103
+	id[SFF8636_OM3_LEN_OFFSET] = 35
+	id[SFF8636_CBL_LEN_OFFSET] = 50
+	copy(id[SFF8636_VENDOR_NAME_START_OFFSET:], "ACME CORP.      ")
+	copy(id[SFF8636_VENDOR_OUI_OFFSET:], []byte{0x00, 0x17, 0x6a})
+	copy(id[SFF8636_VENDOR_PN_START_OFFSET:], "QSFP-100G-SR4   ")
+	copy(id[SFF8636_VENDOR_REV_START_OFFSET:], "A0")
+	copy(id[SFF8636_VENDOR_SN_START_OFFSET:], "SN0123456789    ")
+	copy(id[SFF8636_DATE_YEAR_OFFSET:], "210315L1")
+	return id
+}
+
+func TestParseSFF8636(t *testing.T) {
+	sff, err := ParseSFF8636(newSFF8636EEPROM())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &SFF8636{
+		Identifier:    "0x11 (QSFP28)",
+		ExtIdentifier: 0xc5,
+		ExtIdentifierDescr: []string{
+			"3.5W max. Power consumption",
+			"4.0W max. Power consumption",
+			"No CLEI code present in Page 02h",
+			"No CDR in TX",
+			"CDR present in RX",
+			"High Power Class (> 3.5 W) not enabled",
+		},
+		Connector:       "0x0c (MPO Parallel Optic)",
+		TransceiverType: []string{"100GBASE-SR4 or 25GBASE-SR"},
+		Encoding:        "0x05 (64B/66B)",
+		BRNominalMbps:   25750,
+		CableLengths:    SFF8636CableLengths{OM3m: 70, CopperOM4m: 50},
+		VendorName:      "ACME CORP.",
+		VendorOUI:       "00:17:6a",
+		VendorPN:        "QSFP-100G-SR4",
+		VendorSN:        "SN0123456789",
+		VendorRev:       "A0",
+		VendorDate:      time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC),
+		VendorLot:       "L1",
+	}
+
+	if !reflect.DeepEqual(sff, expected) {
+		t.Errorf("expected %+v, got %+v", expected, sff)
+	}
+
+	if _, err := ParseSFF8636(make([]byte, 128)); err == nil {
+		t.Error("expected an error for a short EEPROM")
+	}
+}
+
+func TestSFF8636PowerClass(t *testing.T) {
+	for _, tt := range []struct {
+		ext   uint8
+		class int
+		watts float64
+	}{
+		{0x00, 1, 1.5},
+		{0x40, 2, 2.0},
+		{0x80, 3, 2.5},
+		{0xc0, 4, 3.5},
+		{0xc1, 5, 4.0},
+		{0xc2, 6, 4.5},
+		{0xc3, 7, 5.0},
+	} {
+		sff := SFF8636{ExtIdentifier: tt.ext}
+		if class := sff.PowerClass(); class != tt.class {
+			t.Errorf("expected class %d for 0x%02x, got %d", tt.class, tt.ext, class)
+		}
+		if watts := sff.PowerConsumptionW(); watts != tt.watts {
+			t.Errorf("expected %.1fW for 0x%02x, got %.1fW", tt.watts, tt.ext, watts)
+		}
+	}
+
+	id := newSFF8636EEPROM()
+	id[SFF8636_PWR_MODE_OFFSET] = SFF8636_HIGH_PWR_ENABLE
+	sff, err := ParseSFF8636(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sff.HighPowerEnabled || sff.ExtIdentifierDescr[len(sff.ExtIdentifierDescr)-1] != "High Power Class (> 3.5 W) enabled" {
+		t.Errorf("expected high power class enabled, got %+v", sff)
+	}
+}
+
+func TestParseSFF8636DOM(t *testing.T) {
+	id := newSFF8636EEPROM()
+	// 36.5 C, 3.3 V
+	binary.BigEndian.PutUint16(id[SFF8636_TEMP_CURR:], 0x2480)
+	binary.BigEndian.PutUint16(id[SFF8636_VCC_CURR:], 33000)
+	for i := 0; i < 4; i++ {
+		// 0.5 mW, 6 mA, 0.8 mW
+		binary.BigEndian.PutUint16(id[SFF8636_RX_PWR_1_OFFSET+2*i:], 5000)
+		binary.BigEndian.PutUint16(id[SFF8636_TX_BIAS_1_OFFSET+2*i:], 3000)
+		binary.BigEndian.PutUint16(id[SFF8636_TX_PWR_1_OFFSET+2*i:], 8000)
+	}
+	// temperature high alarm, vcc low warning
+	id[SFF8636_TEMP_AW_OFFSET] = 0x80
+	id[SFF8636_VCC_AW_OFFSET] = 0x10
+	// channel 1 rx power low alarm, channel 4 rx power high warning
+	id[SFF8636_RX_PWR_12_AW_OFFSET] = 0x40
+	id[SFF8636_RX_PWR_34_AW_OFFSET] = 0x02
+	// channel 2 tx bias high alarm
+	id[SFF8636_TX_BIAS_12_AW_OFFSET] = 0x08
+
+	dom, err := ParseSFF8636DOM(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !floatEquals(dom.Temperature, 36.5) || !floatEquals(dom.Voltage, 3.3) {
+		t.Errorf("unexpected temperature or voltage: %+v", dom)
+	}
+	for i := 0; i < 4; i++ {
+		if !floatEquals(dom.ChannelRXPower[i], 0.5) || !floatEquals(dom.ChannelTXBias[i], 6) ||
+			!floatEquals(dom.ChannelTXPower[i], 0.8) {
+			t.Errorf("unexpected channel %d values: %+v", i+1, dom)
+		}
+	}
+
+	if expected := (SFF8636DOMFlags{TempHigh: true, RXPowerLow: 0x1, TXBiasHigh: 0x2}); dom.Alarms != expected {
+		t.Errorf("expected alarms %+v, got %+v", expected, dom.Alarms)
+	}
+	if expected := (SFF8636DOMFlags{VccLow: true, RXPowerHigh: 0x8}); dom.Warnings != expected {
+		t.Errorf("expected warnings %+v, got %+v", expected, dom.Warnings)
+	}
+
+	if _, err := ParseSFF8636DOM(make([]byte, 32)); err == nil {
+		t.Error("expected an error for a short EEPROM")
+	}
+}
+
+func TestParseSFF8636ChannelMonitors(t *testing.T) {
+	id := newSFF8636EEPROM()
+	for i := 0; i < 4; i++ {
+		binary.BigEndian.PutUint16(id[SFF8636_RX_PWR_1_OFFSET+2*i:], uint16(1000*(i+1)))
+		binary.BigEndian.PutUint16(id[SFF8636_TX_BIAS_1_OFFSET+2*i:], 3000)
+		binary.BigEndian.PutUint16(id[SFF8636_TX_PWR_1_OFFSET+2*i:], 8000)
+	}
+	// channel 3 rx loss of signal, channel 2 tx fault
+	id[SFF8636_LOS_AW_OFFSET] = 0x04
+	id[SFF8636_FAULT_AW_OFFSET] = 0x02
+	// channel 1 rx power low alarm, channel 4 rx power high warning
+	id[SFF8636_RX_PWR_12_AW_OFFSET] = 0x40
+	id[SFF8636_RX_PWR_34_AW_OFFSET] = 0x02
+
+	channels, err := ParseSFF8636ChannelMonitors(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, channel := range channels {
+		if !floatEquals(channel.RXPowermW, 0.1*float64(i+1)) || !floatEquals(channel.TXBiasmA, 6) ||
+			!floatEquals(channel.TXPowermW, 0.8) {
+			t.Errorf("unexpected channel %d values: %+v", i+1, channel)
+		}
+		if channel.RXLos != (i == 2) || channel.TXFault != (i == 1) || channel.RXPowerAlarm != (i == 0) ||
+			channel.TXPowerAlarm || channel.TXBiasAlarm {
+			t.Errorf("unexpected channel %d flags: %+v", i+1, channel)
+		}
+	}
+
+	if _, err := ParseSFF8636ChannelMonitors(id[:SFF8636_TX_PWR_1_OFFSET]); err == nil {
+		t.Error("expected an error for a short EEPROM")
+	}
+}
+
+func TestParseSFF8636Thresholds(t *testing.T) {
+	id := newSFF8636EEPROM()
+	binary.BigEndian.PutUint16(id[SFF8636_TEMP_HALRM:], 75*256)
+	binary.BigEndian.PutUint16(id[SFF8636_TEMP_LALRM:], uint16(0xffff-5*256+1))
+	binary.BigEndian.PutUint16(id[SFF8636_TEMP_HWARN:], 70*256)
+	binary.BigEndian.PutUint16(id[SFF8636_TEMP_LWARN:], 0)
+	binary.BigEndian.PutUint16(id[SFF8636_VCC_HALRM:], 36000)
+	binary.BigEndian.PutUint16(id[SFF8636_VCC_LALRM:], 30000)
+	binary.BigEndian.PutUint16(id[SFF8636_VCC_HWARN:], 34650)
+	binary.BigEndian.PutUint16(id[SFF8636_VCC_LWARN:], 31350)
+	binary.BigEndian.PutUint16(id[SFF8636_RX_PWR_HALRM:], 20000)
+	binary.BigEndian.PutUint16(id[SFF8636_RX_PWR_LALRM:], 100)
+	binary.BigEndian.PutUint16(id[SFF8636_RX_PWR_HWARN:], 15000)
+	binary.BigEndian.PutUint16(id[SFF8636_RX_PWR_LWARN:], 500)
+	binary.BigEndian.PutUint16(id[SFF8636_TX_BIAS_HALRM:], 5000)
+	binary.BigEndian.PutUint16(id[SFF8636_TX_BIAS_LALRM:], 500)
+	binary.BigEndian.PutUint16(id[SFF8636_TX_BIAS_HWARN:], 4500)
+	binary.BigEndian.PutUint16(id[SFF8636_TX_BIAS_LWARN:], 1000)
+	binary.BigEndian.PutUint16(id[SFF8636_TX_PWR_HALRM:], 20000)
+	binary.BigEndian.PutUint16(id[SFF8636_TX_PWR_LALRM:], 1000)
+	binary.BigEndian.PutUint16(id[SFF8636_TX_PWR_HWARN:], 15000)
+	binary.BigEndian.PutUint16(id[SFF8636_TX_PWR_LWARN:], 2000)
+
+	thresholds, err := ParseSFF8636Thresholds(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []float64{
+		thresholds.TempHighAlarm, thresholds.TempLowAlarm, thresholds.TempHighWarn, thresholds.TempLowWarn,
+		thresholds.VoltHighAlarm, thresholds.VoltLowAlarm, thresholds.VoltHighWarn, thresholds.VoltLowWarn,
+		thresholds.RXPowerHighAlarm, thresholds.RXPowerLowAlarm, thresholds.RXPowerHighWarn, thresholds.RXPowerLowWarn,
+		thresholds.TXBiasHighAlarm, thresholds.TXBiasLowAlarm, thresholds.TXBiasHighWarn, thresholds.TXBiasLowWarn,
+		thresholds.TXPowerHighAlarm, thresholds.TXPowerLowAlarm, thresholds.TXPowerHighWarn, thresholds.TXPowerLowWarn,
+	}
+	want := []float64{
+		75, -5, 70, 0,
+		3.6, 3, 3.465, 3.135,
+		2, 0.01, 1.5, 0.05,
+		10, 1, 9, 2,
+		2, 0.1, 1.5, 0.2,
+	}
+	for i := range want {
+		if !floatEquals(got[i], want[i]) {
+			t.Fatalf("unexpected thresholds: %+v", thresholds)
+		}
+	}
+
+	if _, err := ParseSFF8636Thresholds(id[:ETH_MODULE_SFF_8636_LEN]); err == nil {
+		t.Error("expected an error without the upper page 03h")
+	}
+
+	dom := &SFF8636DOM{
+		Temperature:    85.5,
+		Voltage:        3.3,
+		ChannelRXPower: [4]float64{1, 1, 1.6, 0.005},
+		ChannelTXBias:  [4]float64{6, 6, 6, 6},
+		ChannelTXPower: [4]float64{1, 1, 1, 1},
+	}
+	violations := dom.CheckThresholds(thresholds)
+	expectedViolations := []string{
+		"temperature high alarm: 85.5°C exceeds 75.0°C",
+		"channel 3 rx power high warning: 1.6000mW exceeds 1.5000mW",
+		"channel 4 rx power low alarm: 0.0050mW below 0.0100mW",
+	}
+	if !reflect.DeepEqual(violations, expectedViolations) {
+		t.Errorf("unexpected violations: %q", violations)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"fmt"
+)
+
+// module management interface standards, see DetectModuleType
+const (
+	MODULE_TYPE_SFF_8079 = "SFF-8079"
+	MODULE_TYPE_SFF_8636 = "SFF-8636"
+	MODULE_TYPE_CMIS     = "CMIS"
+)
+
+// Module is the decoded identification of a plug-in module, either a
+// *SFF8079, a *SFF8636 or a *CMISModule.
+type Module interface {
+	// ModuleType returns the name of the management interface standard
+	// used by the module.
+	ModuleType() string
+}
+
+// ModuleType returns the standard of the module EEPROM.
+func (m *SFF8079) ModuleType() string {
+	return MODULE_TYPE_SFF_8079
+}
+
+// ModuleType returns the standard of the module EEPROM.
+func (m *SFF8636) ModuleType() string {
+	return MODULE_TYPE_SFF_8636
+}
+
+// ModuleType returns the standard of the module EEPROM.
+func (m *CMISModule) ModuleType() string {
+	return MODULE_TYPE_CMIS
+}
+
+// DetectModuleType returns the management interface standard of the given
+// module EEPROM, one of the MODULE_TYPE_* constants, according to the
+// SFF-8024 identifier found in its first byte.
+func DetectModuleType(id []byte) (string, error) {
+	if len(id) == 0 {
+		return "", fmt.Errorf("empty module EEPROM")
+	}
+
+	switch id[0] {
+	case SFF8024_ID_SOLDERED, SFF8024_ID_SFP, SFF8024_ID_DWDM_SFP:
+		return MODULE_TYPE_SFF_8079, nil
+	case SFF8024_ID_QSFP, SFF8024_ID_QSFP_PLUS, SFF8024_ID_QSFP28:
+		return MODULE_TYPE_SFF_8636, nil
+	case SFF8024_ID_QSFP_DD, SFF8024_ID_OSFP, SFF8024_ID_DSFP, SFF8024_ID_QSFP_PLUS_CMIS:
+		return MODULE_TYPE_CMIS, nil
+	}
+
+	return "", fmt.Errorf("unsupported module identifier %s", sff8024ShowIdentifier(id[0]))
+}
+
+// ParseModuleEeprom decodes the given module EEPROM with the parser selected
+// by DetectModuleType.
+func ParseModuleEeprom(id []byte) (Module, error) {
+	moduleType, err := DetectModuleType(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// return the concrete values only on success, a nil pointer would
+	// otherwise end up in a non nil Module
+	switch moduleType {
+	case MODULE_TYPE_SFF_8079:
+		sff, err := ParseSFF8079(id)
+		if err != nil {
+			return nil, err
+		}
+		return sff, nil
+	case MODULE_TYPE_SFF_8636:
+		sff, err := ParseSFF8636(id)
+		if err != nil {
+			return nil, err
+		}
+		return sff, nil
+	default:
+		cmis, err := ParseCMIS(id)
+		if err != nil {
+			return nil, err
+		}
+		return cmis, nil
+	}
+}
+
+// ModuleEepromParsed returns the decoded module EEPROM of the given
+// interface name, see ParseModuleEeprom.
+func (e *Ethtool) ModuleEepromParsed(intf string) (Module, error) {
+	id, err := e.ModuleEeprom(intf)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseModuleEeprom(id)
+}
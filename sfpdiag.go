@@ -0,0 +1,245 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// SFF-8472 A0 page offsets, the A2 page follows it in the module EEPROM
+// dump, see ETH_MODULE_SFF_8472
+const (
+	SFF_A0_DOM          = 92
+	SFF_A0_OPTIONS      = 93
+	SFF_A0_COMP         = 94
+	SFF_A0_DOM_PWRT     = 1 << 3 // received power is an average, OMA otherwise
+	SFF_A0_DOM_EXTCAL   = 1 << 4 // externally calibrated
+	SFF_A0_DOM_INTCAL   = 1 << 5 // internally calibrated
+	SFF_A0_DOM_IMPL     = 1 << 6 // digital diagnostic monitoring implemented
+	SFF_A0_OPTIONS_AW   = 1 << 7 // alarm and warning flags implemented
+	SFF_A2_PAGE_OFFSET  = 256
+	SFF_A2_PAGE_LEN     = 256
+	SFF_A2_TEMP         = 96
+	SFF_A2_TEMP_HALRM   = 0
+	SFF_A2_TEMP_LALRM   = 2
+	SFF_A2_TEMP_HWARN   = 4
+	SFF_A2_TEMP_LWARN   = 6
+	SFF_A2_VCC          = 98
+	SFF_A2_VCC_HALRM    = 8
+	SFF_A2_VCC_LALRM    = 10
+	SFF_A2_VCC_HWARN    = 12
+	SFF_A2_VCC_LWARN    = 14
+	SFF_A2_BIAS         = 100
+	SFF_A2_BIAS_HALRM   = 16
+	SFF_A2_BIAS_LALRM   = 18
+	SFF_A2_BIAS_HWARN   = 20
+	SFF_A2_BIAS_LWARN   = 22
+	SFF_A2_TX_PWR       = 102
+	SFF_A2_TX_PWR_HALRM = 24
+	SFF_A2_TX_PWR_LALRM = 26
+	SFF_A2_TX_PWR_HWARN = 28
+	SFF_A2_TX_PWR_LWARN = 30
+	SFF_A2_RX_PWR       = 104
+	SFF_A2_RX_PWR_HALRM = 32
+	SFF_A2_RX_PWR_LALRM = 34
+	SFF_A2_RX_PWR_HWARN = 36
+	SFF_A2_RX_PWR_LWARN = 38
+	SFF_A2_ALRM_FLG     = 112
+	SFF_A2_WARN_FLG     = 116
+
+	// external calibration constants
+	SFF_A2_CAL_RXPWR4    = 56
+	SFF_A2_CAL_RXPWR3    = 60
+	SFF_A2_CAL_RXPWR2    = 64
+	SFF_A2_CAL_RXPWR1    = 68
+	SFF_A2_CAL_RXPWR0    = 72
+	SFF_A2_CAL_TXI_SLP   = 76
+	SFF_A2_CAL_TXI_OFF   = 78
+	SFF_A2_CAL_TXPWR_SLP = 80
+	SFF_A2_CAL_TXPWR_OFF = 82
+	SFF_A2_CAL_T_SLP     = 84
+	SFF_A2_CAL_T_OFF     = 86
+	SFF_A2_CAL_V_SLP     = 88
+	SFF_A2_CAL_V_OFF     = 90
+)
+
+// SFF8472Flags contains the alarm or warning flags of the SFF-8472
+// diagnostics.
+type SFF8472Flags struct {
+	TempHigh    bool `json:"temp_high"`
+	TempLow     bool `json:"temp_low"`
+	VccHigh     bool `json:"vcc_high"`
+	VccLow      bool `json:"vcc_low"`
+	TXBiasHigh  bool `json:"tx_bias_high"`
+	TXBiasLow   bool `json:"tx_bias_low"`
+	TXPowerHigh bool `json:"tx_power_high"`
+	TXPowerLow  bool `json:"tx_power_low"`
+	RXPowerHigh bool `json:"rx_power_high"`
+	RXPowerLow  bool `json:"rx_power_low"`
+}
+
+// SFF8472DOM contains the real-time diagnostic monitoring values of a SFP
+// module, see SFF-8472.
+type SFF8472DOM struct {
+	TemperatureC float64      `json:"temperature_c"` // module temperature in degrees Celsius
+	VoltageV     float64      `json:"voltage_v"`     // supply voltage in volts
+	TXBiasmA     float64      `json:"tx_bias_ma"`    // laser bias current in milliamperes
+	TXPowermW    float64      `json:"tx_power_mw"`   // transmitted optical power in milliwatts
+	TXPowerdBm   float64      `json:"tx_power_dbm"`
+	RXPowermW    float64      `json:"rx_power_mw"` // received optical power in milliwatts
+	RXPowerdBm   float64      `json:"rx_power_dbm"`
+	Alarms       SFF8472Flags `json:"alarms"`
+	Warnings     SFF8472Flags `json:"warnings"`
+}
+
+// HasAlarm returns true if the high or the low alarm of the given value,
+// one of "temperature", "voltage", "tx_bias", "tx_power" or "rx_power", is
+// raised. Unknown values have no alarm.
+func (s SFF8472DOM) HasAlarm(field string) bool {
+	switch field {
+	case "temperature":
+		return s.Alarms.TempHigh || s.Alarms.TempLow
+	case "voltage":
+		return s.Alarms.VccHigh || s.Alarms.VccLow
+	case "tx_bias":
+		return s.Alarms.TXBiasHigh || s.Alarms.TXBiasLow
+	case "tx_power":
+		return s.Alarms.TXPowerHigh || s.Alarms.TXPowerLow
+	case "rx_power":
+		return s.Alarms.RXPowerHigh || s.Alarms.RXPowerLow
+	}
+	return false
+}
+
+// SFF8472 contains the digital diagnostics of a SFP module, see SFF-8472.
+type SFF8472 struct {
+	ExternalCalibration bool `json:"external_calibration"` // values are computed from the calibration constants
+	RXPowerAverage      bool `json:"rx_power_average"`     // received power is an average, OMA otherwise
+	FlagsImplemented    bool `json:"flags_implemented"`    // Alarms and Warnings are reported by the module
+	SFF8472DOM
+}
+
+// mWToDBm converts a power in milliwatts to dBm, a null power is reported
+// as -40 dBm, the usual floor of the modules.
+func mWToDBm(mw float64) float64 {
+	if mw <= 0 {
+		return -40
+	}
+	return 10 * math.Log10(mw)
+}
+
+func decodeSFF8472Flags(b []byte) SFF8472Flags {
+	return SFF8472Flags{
+		TempHigh:    b[0]&(1<<7) != 0,
+		TempLow:     b[0]&(1<<6) != 0,
+		VccHigh:     b[0]&(1<<5) != 0,
+		VccLow:      b[0]&(1<<4) != 0,
+		TXBiasHigh:  b[0]&(1<<3) != 0,
+		TXBiasLow:   b[0]&(1<<2) != 0,
+		TXPowerHigh: b[0]&(1<<1) != 0,
+		TXPowerLow:  b[0]&(1<<0) != 0,
+		RXPowerHigh: b[1]&(1<<7) != 0,
+		RXPowerLow:  b[1]&(1<<6) != 0,
+	}
+}
+
+// applyExternalCalibration converts the raw A2 page values of the given
+// externally calibrated SFP module EEPROM to degrees Celsius, volts,
+// milliamperes and milliwatts. The calibration constants of the A2 page are
+// a slope and an offset for all the values but the received power, which
+// uses a polynomial of the raw value with 5 coefficients.
+func applyExternalCalibration(id []byte, rawTemp, rawVcc, rawTxBias, rawTxPwr, rawRxPwr uint16) (temp, vcc, txBias, txPwr, rxPwr float64) {
+	a2 := id[SFF_A2_PAGE_OFFSET:]
+
+	slope := func(offset int) float64 {
+		return float64(binary.BigEndian.Uint16(a2[offset:])) / 256
+	}
+	offset := func(offset int) float64 {
+		return float64(int16(binary.BigEndian.Uint16(a2[offset:])))
+	}
+	coef := func(offset int) float64 {
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(a2[offset:])))
+	}
+
+	temp = (slope(SFF_A2_CAL_T_SLP)*float64(int16(rawTemp)) + offset(SFF_A2_CAL_T_OFF)) / 256
+	vcc = (slope(SFF_A2_CAL_V_SLP)*float64(rawVcc) + offset(SFF_A2_CAL_V_OFF)) * 100e-6
+	txBias = (slope(SFF_A2_CAL_TXI_SLP)*float64(rawTxBias) + offset(SFF_A2_CAL_TXI_OFF)) * 2e-3
+	txPwr = (slope(SFF_A2_CAL_TXPWR_SLP)*float64(rawTxPwr) + offset(SFF_A2_CAL_TXPWR_OFF)) * 1e-4
+
+	rx := float64(rawRxPwr)
+	rxPwr = (coef(SFF_A2_CAL_RXPWR0) +
+		coef(SFF_A2_CAL_RXPWR1)*rx +
+		coef(SFF_A2_CAL_RXPWR2)*rx*rx +
+		coef(SFF_A2_CAL_RXPWR3)*rx*rx*rx +
+		coef(SFF_A2_CAL_RXPWR4)*rx*rx*rx*rx) * 1e-4
+
+	return temp, vcc, txBias, txPwr, rxPwr
+}
+
+// ParseSFF8472 decodes the digital diagnostics of the given SFP module
+// EEPROM, made of the A0 page followed by the A2 page.
+func ParseSFF8472(id []byte) (*SFF8472, error) {
+	if len(id) < SFF_A2_PAGE_OFFSET+SFF_A2_PAGE_LEN {
+		return nil, fmt.Errorf("SFF-8472 EEPROM too short: %d bytes", len(id))
+	}
+
+	if id[SFF_A0_DOM]&SFF_A0_DOM_IMPL == 0 {
+		return nil, fmt.Errorf("SFF-8472 digital diagnostics not implemented")
+	}
+
+	a2 := id[SFF_A2_PAGE_OFFSET:]
+	rawTemp := binary.BigEndian.Uint16(a2[SFF_A2_TEMP:])
+	rawVcc := binary.BigEndian.Uint16(a2[SFF_A2_VCC:])
+	rawTXBias := binary.BigEndian.Uint16(a2[SFF_A2_BIAS:])
+	rawTXPower := binary.BigEndian.Uint16(a2[SFF_A2_TX_PWR:])
+	rawRXPower := binary.BigEndian.Uint16(a2[SFF_A2_RX_PWR:])
+
+	diag := &SFF8472{
+		ExternalCalibration: id[SFF_A0_DOM]&SFF_A0_DOM_EXTCAL != 0,
+		RXPowerAverage:      id[SFF_A0_DOM]&SFF_A0_DOM_PWRT != 0,
+	}
+
+	// values are in units of 1/256 degree, 100 uV, 2 uA and 0.1 uW
+	if diag.ExternalCalibration {
+		diag.TemperatureC, diag.VoltageV, diag.TXBiasmA, diag.TXPowermW, diag.RXPowermW =
+			applyExternalCalibration(id, rawTemp, rawVcc, rawTXBias, rawTXPower, rawRXPower)
+	} else {
+		diag.TemperatureC = float64(int16(rawTemp)) / 256
+		diag.VoltageV = float64(rawVcc) * 100e-6
+		diag.TXBiasmA = float64(rawTXBias) * 2e-3
+		diag.TXPowermW = float64(rawTXPower) * 1e-4
+		diag.RXPowermW = float64(rawRXPower) * 1e-4
+	}
+
+	diag.TXPowerdBm = mWToDBm(diag.TXPowermW)
+	diag.RXPowerdBm = mWToDBm(diag.RXPowermW)
+
+	if id[SFF_A0_OPTIONS]&SFF_A0_OPTIONS_AW != 0 {
+		diag.FlagsImplemented = true
+		diag.Alarms = decodeSFF8472Flags(a2[SFF_A2_ALRM_FLG:])
+		diag.Warnings = decodeSFF8472Flags(a2[SFF_A2_WARN_FLG:])
+	}
+
+	return diag, nil
+}
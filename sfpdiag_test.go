@@ -0,0 +1,162 @@
+/*
+ *
+ * Licensed to the Apache Software Foundation (ASF) under one
+ * or more contributor license agreements.  See the NOTICE file
+ * distributed with this work for additional information
+ * regarding copyright ownership.  The ASF licenses this file
+ * to you under the Apache License, Version 2.0 (the
+ * "License"); you may not use this file except in compliance
+ * with the License.  You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing,
+ * software distributed under the License is distributed on an
+ * "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+ * KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations
+ * under the License.
+ *
+ */
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func newSFF8472EEPROM() []byte {
+	id := make([]byte, ETH_MODULE_SFF_8472_LEN)
+	id[0] = 0x03
+	id[SFF_A0_DOM] = SFF_A0_DOM_IMPL | SFF_A0_DOM_INTCAL | SFF_A0_DOM_PWRT
+	id[SFF_A0_OPTIONS] = SFF_A0_OPTIONS_AW
+
+	a2 := id[SFF_A2_PAGE_OFFSET:]
+	binary.BigEndian.PutUint16(a2[SFF_A2_TEMP:], 0xf600)  // -10 C
+	binary.BigEndian.PutUint16(a2[SFF_A2_VCC:], 33000)    // 3.3 V
+	binary.BigEndian.PutUint16(a2[SFF_A2_BIAS:], 3000)    // 6 mA
+	binary.BigEndian.PutUint16(a2[SFF_A2_TX_PWR:], 10000) // 1 mW
+	binary.BigEndian.PutUint16(a2[SFF_A2_RX_PWR:], 1000)  // 0.1 mW
+	a2[SFF_A2_ALRM_FLG] = 1 << 7
+	a2[SFF_A2_WARN_FLG+1] = 1 << 6
+
+	return id
+}
+
+func floatEquals(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestParseSFF8472(t *testing.T) {
+	diag, err := ParseSFF8472(newSFF8472EEPROM())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !floatEquals(diag.TemperatureC, -10) || !floatEquals(diag.VoltageV, 3.3) ||
+		!floatEquals(diag.TXBiasmA, 6) || !floatEquals(diag.TXPowermW, 1) ||
+		!floatEquals(diag.RXPowermW, 0.1) {
+		t.Errorf("unexpected diagnostics %+v", diag)
+	}
+
+	if !floatEquals(diag.TXPowerdBm, 0) || !floatEquals(diag.RXPowerdBm, -10) {
+		t.Errorf("unexpected power in dBm: %f %f", diag.TXPowerdBm, diag.RXPowerdBm)
+	}
+
+	if !diag.RXPowerAverage || diag.ExternalCalibration {
+		t.Errorf("unexpected diagnostics type %+v", diag)
+	}
+
+	if !diag.FlagsImplemented || !diag.Alarms.TempHigh || diag.Alarms.RXPowerLow || !diag.Warnings.RXPowerLow {
+		t.Errorf("unexpected flags %+v %+v", diag.Alarms, diag.Warnings)
+	}
+}
+
+func TestSFF8472DOMHasAlarm(t *testing.T) {
+	diag, err := ParseSFF8472(newSFF8472EEPROM())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for field, expected := range map[string]bool{
+		"temperature": true,
+		"voltage":     false,
+		"tx_bias":     false,
+		"tx_power":    false,
+		"rx_power":    false, // only a warning
+		"unknown":     false,
+	} {
+		if diag.HasAlarm(field) != expected {
+			t.Errorf("expected alarm %v for %s", expected, field)
+		}
+	}
+
+	b, err := json.Marshal(diag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"temperature_c":-10,`) {
+		t.Errorf("expected the DOM values at the top level of %s", b)
+	}
+}
+
+func TestParseSFF8472ExternalCalibration(t *testing.T) {
+	id := newSFF8472EEPROM()
+	id[SFF_A0_DOM] = SFF_A0_DOM_IMPL | SFF_A0_DOM_EXTCAL
+
+	a2 := id[SFF_A2_PAGE_OFFSET:]
+	binary.BigEndian.PutUint16(a2[SFF_A2_CAL_T_SLP:], 2*256)  // x2
+	binary.BigEndian.PutUint16(a2[SFF_A2_CAL_T_OFF:], 0xff00) // -1 C
+	binary.BigEndian.PutUint16(a2[SFF_A2_CAL_V_SLP:], 256)
+	binary.BigEndian.PutUint16(a2[SFF_A2_CAL_TXI_SLP:], 256)
+	binary.BigEndian.PutUint16(a2[SFF_A2_CAL_TXI_OFF:], 500) // +1 mA
+	binary.BigEndian.PutUint16(a2[SFF_A2_CAL_TXPWR_SLP:], 128)
+	binary.BigEndian.PutUint32(a2[SFF_A2_CAL_RXPWR1:], math.Float32bits(2))
+	binary.BigEndian.PutUint32(a2[SFF_A2_CAL_RXPWR0:], math.Float32bits(100))
+
+	diag, err := ParseSFF8472(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !floatEquals(diag.TemperatureC, -21) || !floatEquals(diag.VoltageV, 3.3) ||
+		!floatEquals(diag.TXBiasmA, 7) || !floatEquals(diag.TXPowermW, 0.5) ||
+		!floatEquals(diag.RXPowermW, 0.21) {
+		t.Errorf("unexpected calibrated diagnostics %+v", diag)
+	}
+}
+
+func TestApplyExternalCalibration(t *testing.T) {
+	id := make([]byte, ETH_MODULE_SFF_8472_LEN)
+
+	a2 := id[SFF_A2_PAGE_OFFSET:]
+	for _, slp := range []int{SFF_A2_CAL_T_SLP, SFF_A2_CAL_V_SLP, SFF_A2_CAL_TXI_SLP, SFF_A2_CAL_TXPWR_SLP} {
+		binary.BigEndian.PutUint16(a2[slp:], 256)
+	}
+	binary.BigEndian.PutUint32(a2[SFF_A2_CAL_RXPWR2:], math.Float32bits(0.5))
+	binary.BigEndian.PutUint32(a2[SFF_A2_CAL_RXPWR4:], math.Float32bits(0.25))
+
+	temp, vcc, txBias, txPwr, rxPwr := applyExternalCalibration(id, 0xff00, 10000, 1000, 2000, 10)
+	if !floatEquals(temp, -1) || !floatEquals(vcc, 1) || !floatEquals(txBias, 2) || !floatEquals(txPwr, 0.2) {
+		t.Errorf("unexpected calibrated values %f %f %f %f", temp, vcc, txBias, txPwr)
+	}
+
+	// 0.5 * 10^2 + 0.25 * 10^4 = 2550 in units of 0.1 uW
+	if !floatEquals(rxPwr, 0.255) {
+		t.Errorf("unexpected calibrated received power %f", rxPwr)
+	}
+}
+
+func TestParseSFF8472Errors(t *testing.T) {
+	if _, err := ParseSFF8472(make([]byte, ETH_MODULE_SFF_8079_LEN)); err == nil {
+		t.Error("expected an error for a short EEPROM")
+	}
+
+	if _, err := ParseSFF8472(make([]byte, ETH_MODULE_SFF_8472_LEN)); err == nil {
+		t.Error("expected an error for a module without diagnostics")
+	}
+}